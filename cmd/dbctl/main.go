@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"ip-geo/internal/config"
+	"ip-geo/internal/downloader"
+)
+
+const usage = `用法: dbctl <命令> -db <名称> [-version <版本>] [-file <文件>]
+
+命令:
+  versions  列出数据库保留的历史版本
+  rollback  回滚到上一个版本，指定-version时切换到该版本
+  import    将不受版本管理的数据库文件导入为第一个版本
+  add       将-file指定的数据库文件加入为新版本并切换到该版本
+
+切换版本后向服务进程发送SIGHUP或调用管理接口使其重新加载。
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command := os.Args[1]
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	name := flags.String("db", "", "数据库名称")
+	version := flags.String("version", "", "切换到的版本ID")
+	file := flags.String("file", "", "加入的数据库文件")
+	flags.Parse(os.Args[2:])
+
+	// 与服务使用同一配置文件
+	cfg := config.GetInstance()
+	if err := cfg.LoadFromFile("config.json"); err != nil && !os.IsNotExist(err) {
+		exitf("加载配置文件失败: %v", err)
+	}
+
+	path, ok := cfg.DatabasePath(*name)
+	if !ok || path == "" {
+		exitf("未知或未配置的数据库: %q", *name)
+	}
+	store := downloader.Versions(path)
+
+	switch command {
+	case "versions":
+		m, err := store.Manifest()
+		if err != nil {
+			exitf("读取版本清单失败: %v", err)
+		}
+		if len(m.Versions) == 0 {
+			fmt.Printf("%s 不受版本管理，可使用import导入\n", path)
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\tID\tSHA256\t构建时间\t下载时间")
+		for _, v := range m.Versions {
+			current := ""
+			if v.ID == m.Current {
+				current = "*"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", current, v.ID, v.SHA256[:16],
+				time.Unix(int64(v.BuildEpoch), 0).UTC().Format(time.RFC3339),
+				v.DownloadedAt.Format(time.RFC3339))
+		}
+		w.Flush()
+	case "rollback":
+		var err error
+		if *version != "" {
+			_, err = store.Activate(*version)
+		} else {
+			_, err = store.Rollback()
+		}
+		if err != nil {
+			exitf("切换版本失败: %v", err)
+		}
+		m, err := store.Manifest()
+		if err != nil {
+			exitf("读取版本清单失败: %v", err)
+		}
+		fmt.Printf("%s 已切换到版本 %s，向服务进程发送SIGHUP以重新加载\n", *name, m.Current)
+	case "import":
+		if err := store.Import(); err != nil {
+			exitf("导入失败: %v", err)
+		}
+		m, err := store.Manifest()
+		if err != nil {
+			exitf("读取版本清单失败: %v", err)
+		}
+		fmt.Printf("%s 当前版本 %s\n", *name, m.Current)
+	case "add":
+		if *file == "" {
+			exitf("必须指定-file")
+		}
+		// 版本存储会移动文件，先复制一份保留原文件
+		tmp := path + ".add"
+		if err := copyFile(*file, tmp); err != nil {
+			exitf("复制文件失败: %v", err)
+		}
+		v, err := store.Add(tmp, "")
+		if err != nil {
+			os.Remove(tmp)
+			exitf("加入版本失败: %v", err)
+		}
+		fmt.Printf("%s 已切换到版本 %s，向服务进程发送SIGHUP以重新加载\n", *name, v.ID)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// copyFile 复制文件
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func exitf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
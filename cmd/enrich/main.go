@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"ip-geo/internal/database"
+	"ip-geo/internal/enrich"
+	"ip-geo/internal/logger"
+	"ip-geo/internal/service"
+)
+
+func main() {
+	input := flag.String("input", "-", "输入文件，-表示标准输入")
+	output := flag.String("output", "-", "输出文件，-表示标准输出")
+	column := flag.Int("column", 0, "IP所在列序号，从0开始")
+	delimiter := flag.String("delimiter", "", "列分隔符，为空时按空白字符分割")
+	pattern := flag.String("regex", "", "提取IP的正则表达式，优先使用名为ip的捕获组，设置后忽略-column")
+	fields := flag.String("fields", strings.Join(enrich.DefaultFields, ","), "追加的地理信息列，逗号分隔")
+	format := flag.String("format", enrich.FormatCSV, "输出格式: csv、tsv或jsonl")
+	workers := flag.Int("workers", 0, "并发查询协程数，默认为CPU核数")
+	header := flag.Bool("header", false, "第一行为表头")
+	flag.Parse()
+
+	// 初始化数据库
+	if err := database.InitializeDB(); err != nil {
+		exitf("初始化数据库失败: %v", err)
+	}
+	defer database.GetInstance().Close()
+
+	var extractor enrich.Extractor = &enrich.ColumnExtractor{
+		Index:     *column,
+		Delimiter: unescape(*delimiter),
+	}
+	if *pattern != "" {
+		re, err := enrich.NewRegexExtractor(*pattern)
+		if err != nil {
+			exitf("%v", err)
+		}
+		extractor = re
+	}
+
+	enricher, err := enrich.NewEnricher(service.GetInstance(), enrich.Options{
+		Extractor: extractor,
+		Fields:    strings.Split(*fields, ","),
+		Format:    *format,
+		Workers:   *workers,
+		Header:    *header,
+	})
+	if err != nil {
+		exitf("%v", err)
+	}
+
+	var r io.Reader = os.Stdin
+	if *input != "-" {
+		f, err := os.Open(*input)
+		if err != nil {
+			exitf("打开输入文件失败: %v", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			exitf("创建输出文件失败: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	stats, err := enricher.Run(r, w)
+	logger.Info("日志富化完成: %s", stats)
+	fmt.Fprintln(os.Stderr, stats)
+	if err != nil {
+		exitf("%v", err)
+	}
+}
+
+// unescape 处理命令行中转义的分隔符，如\t
+func unescape(s string) string {
+	switch s {
+	case `\t`:
+		return "\t"
+	case `\s`:
+		return ""
+	}
+	return s
+}
+
+// exitf 输出错误并退出
+func exitf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ip-geo/internal/logger"
+	"ip-geo/internal/mmdbmerge"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+func main() {
+	cityPath := flag.String("city", "mmdb/GeoIP2-City.mmdb", "GeoIP2 City或Country格式的数据库")
+	output := flag.String("output", "internal/fallback/country.mmdb", "输出的国家级数据库，使用fallback构建标签时内嵌到程序中")
+	verify := flag.Bool("verify", true, "写入后重新打开数据库，逐个网段核对洲和国家")
+	flag.Parse()
+
+	start := time.Now()
+
+	city, err := maxminddb.Open(*cityPath)
+	if err != nil {
+		exitf("打开City数据库失败: %v", err)
+	}
+	defer city.Close()
+
+	tree, count, err := mmdbmerge.ReduceToCountry(city)
+	if err != nil {
+		exitf("生成国家级数据库失败: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*output), 0755); err != nil {
+		exitf("创建输出目录失败: %v", err)
+	}
+	if err := tree.WriteFile(*output); err != nil {
+		exitf("写入数据库失败: %v", err)
+	}
+
+	if *verify {
+		if err := mmdbmerge.VerifyCountry(*output, city); err != nil {
+			exitf("校验数据库失败: %v", err)
+		}
+	}
+
+	info, err := os.Stat(*output)
+	if err != nil {
+		exitf("%v", err)
+	}
+	logger.Info("生成国家级数据库完成: %s, %d 个网段", *output, count)
+	fmt.Fprintf(os.Stderr, "已写入 %s: %d 个网段，%d 字节，耗时 %s\n", *output, count, info.Size(), time.Since(start).Round(time.Millisecond))
+}
+
+// exitf 输出错误并退出
+func exitf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ip-geo/internal/logger"
+	"ip-geo/internal/mmdbmerge"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+func main() {
+	cityPath := flag.String("city", "mmdb/GeoIP2-City.mmdb", "GeoIP2 City数据库")
+	geoCNPath := flag.String("geocn", "mmdb/GeoCN.mmdb", "GeoCN数据库")
+	output := flag.String("output", "mmdb/GeoIP2-City-GeoCN.mmdb", "输出的合并数据库")
+	verify := flag.Bool("verify", true, "写入后重新打开数据库，逐个网段核对合并结果")
+	flag.Parse()
+
+	start := time.Now()
+
+	city, err := maxminddb.Open(*cityPath)
+	if err != nil {
+		exitf("打开City数据库失败: %v", err)
+	}
+	defer city.Close()
+
+	geoCN, err := maxminddb.Open(*geoCNPath)
+	if err != nil {
+		exitf("打开GeoCN数据库失败: %v", err)
+	}
+	defer geoCN.Close()
+
+	tree, stats, err := mmdbmerge.MergeGeoCN(city, geoCN)
+	if err != nil {
+		exitf("合并数据库失败: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*output), 0755); err != nil {
+		exitf("创建输出目录失败: %v", err)
+	}
+	if err := tree.WriteFile(*output); err != nil {
+		exitf("写入数据库失败: %v", err)
+	}
+
+	if *verify {
+		if err := mmdbmerge.VerifyGeoCN(*output, city, geoCN); err != nil {
+			exitf("校验数据库失败: %v", err)
+		}
+	}
+
+	logger.Info("合并GeoCN数据库完成: %s, %s", *output, stats)
+	fmt.Fprintf(os.Stderr, "已写入 %s: %s，耗时 %s\n", *output, stats, time.Since(start).Round(time.Millisecond))
+}
+
+// exitf 输出错误并退出
+func exitf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ip-geo/internal/logger"
+	"ip-geo/internal/override"
+)
+
+func main() {
+	input := flag.String("input", "", "网段数据文件，支持CSV/JSON/YAML，格式与覆盖文件相同")
+	output := flag.String("output", "mmdb/IPGeo-Custom.mmdb", "输出的MMDB文件")
+	description := flag.String("description", "", "数据库描述")
+	verify := flag.Bool("verify", true, "写入后重新打开数据库并逐个网段核对")
+	flag.Parse()
+
+	if *input == "" {
+		exitf("必须指定-input")
+	}
+
+	start := time.Now()
+	entries, err := override.LoadFile(*input)
+	if err != nil {
+		exitf("%v", err)
+	}
+
+	tree, err := override.Build(entries, *description)
+	if err != nil {
+		exitf("编译数据库失败: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*output), 0755); err != nil {
+		exitf("创建输出目录失败: %v", err)
+	}
+	if err := tree.WriteFile(*output); err != nil {
+		exitf("写入数据库失败: %v", err)
+	}
+
+	if *verify {
+		if err := override.VerifyFile(*output, entries); err != nil {
+			exitf("校验数据库失败: %v", err)
+		}
+	}
+
+	logger.Info("编译自定义数据库完成: %s, %d 个网段", *output, len(entries))
+	fmt.Fprintf(os.Stderr, "已写入 %s: %d 个网段，耗时 %s\n", *output, len(entries), time.Since(start).Round(time.Millisecond))
+}
+
+// exitf 输出错误并退出
+func exitf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
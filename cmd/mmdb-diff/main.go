@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"ip-geo/internal/mmdbdiff"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+func main() {
+	oldPath := flag.String("old", "", "旧版本数据库")
+	newPath := flag.String("new", "", "新版本数据库")
+	schema := flag.String("schema", "", "记录格式，为空时根据数据库类型自动识别")
+	csvPath := flag.String("csv", "", "输出完整差异的CSV文件，-表示标准输出")
+	top := flag.Int("top", 20, "按国家和ASN汇总时列出的数量，0表示全部")
+	jsonOutput := flag.Bool("json", false, "以JSON格式输出汇总")
+	flag.Parse()
+
+	if *oldPath == "" || *newPath == "" {
+		exitf("必须指定-old和-new")
+	}
+
+	oldReader, err := maxminddb.Open(*oldPath)
+	if err != nil {
+		exitf("打开旧版本数据库失败: %v", err)
+	}
+	defer oldReader.Close()
+
+	newReader, err := maxminddb.Open(*newPath)
+	if err != nil {
+		exitf("打开新版本数据库失败: %v", err)
+	}
+	defer newReader.Close()
+
+	opts := mmdbdiff.Options{Schema: *schema}
+	var csvWriter *mmdbdiff.CSVWriter
+	if *csvPath != "" {
+		out := os.Stdout
+		if *csvPath != "-" {
+			f, err := os.Create(*csvPath)
+			if err != nil {
+				exitf("创建CSV文件失败: %v", err)
+			}
+			defer f.Close()
+			out = f
+		}
+		csvWriter = mmdbdiff.NewCSVWriter(out)
+		opts.OnChange = csvWriter.Write
+	}
+
+	report, err := mmdbdiff.Diff(oldReader, newReader, opts)
+	if err != nil {
+		exitf("比较数据库失败: %v", err)
+	}
+	if csvWriter != nil {
+		if err := csvWriter.Flush(); err != nil {
+			exitf("写入CSV文件失败: %v", err)
+		}
+	}
+
+	// CSV输出到标准输出时汇总写入标准错误
+	summary := os.Stdout
+	if *csvPath == "-" {
+		summary = os.Stderr
+	}
+	if *jsonOutput {
+		encoder := json.NewEncoder(summary)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			exitf("输出汇总失败: %v", err)
+		}
+		return
+	}
+	report.WriteSummary(summary, *top)
+}
+
+// exitf 输出错误并退出
+func exitf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
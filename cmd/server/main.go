@@ -1,19 +1,31 @@
 package main
 
 import (
-	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
-	"ip-geo/internal/api/handler"
+	"ip-geo/internal/api"
+	"ip-geo/internal/config"
 	"ip-geo/internal/database"
+	"ip-geo/internal/dnsserver"
 	"ip-geo/internal/downloader"
+	"ip-geo/internal/grpcapi"
 	"ip-geo/internal/logger"
-	"ip-geo/internal/middleware"
+	"ip-geo/internal/server"
+	"ip-geo/internal/service"
+	"ip-geo/internal/whois"
 )
 
 func main() {
-	// 确保MMDB文件存在
+	// 下载数据库时需要使用配置中的下载来源
+	if err := config.GetInstance().LoadFromFile("config.json"); err != nil {
+		logger.Warn("加载配置文件失败，使用默认配置: %v", err)
+	}
+
+	// 确保MMDB文件存在，下载失败的数据库之后在后台重试
 	if err := downloader.EnsureMMDBFiles(); err != nil {
-		logger.Fatal("确保MMDB文件存在失败: %v", err)
+		logger.Error("下载数据库失败: %v", err)
 	}
 
 	// 初始化数据库
@@ -22,36 +34,59 @@ func main() {
 	}
 	logger.Info("数据库初始化成功")
 
+	// 部分数据库不可用时以可用的数据库启动，在后台重试
+	if unavailable := service.GetInstance().UnavailableSources(); len(unavailable) > 0 {
+		logger.Warn("以下数据库不可用，将在后台重试: %v", unavailable)
+		go service.GetInstance().RetryUnavailable()
+	}
+
 	// 确保在程序退出时关闭数据库连接
 	defer func() {
 		db := database.GetInstance()
 		db.Close()
 	}()
 
-	// 创建路由
-	mux := http.NewServeMux()
-
-	// 注册路由处理器
-	ipHandler := handler.NewIPHandler()
+	// 收到SIGHUP信号时重新加载覆盖数据等运行时数据
+	go func() {
+		sighup := make(chan os.Signal, 1)
+		signal.Notify(sighup, syscall.SIGHUP)
+		for range sighup {
+			logger.Info("收到SIGHUP信号，重新加载数据")
+			if err := service.GetInstance().Reload(); err != nil {
+				logger.Error("重新加载数据失败: %v", err)
+			}
+		}
+	}()
 
-	// 包装所有处理器以支持CORS
-	corsHandler := middleware.CORS(mux)
+	// gRPC服务与HTTP服务使用同一个IPService
+	if cfg := &config.GetInstance().GRPC; cfg.Enabled {
+		go func() {
+			if err := grpcapi.ListenAndServe(cfg, service.GetInstance()); err != nil {
+				logger.Fatal("gRPC服务启动失败: %v", err)
+			}
+		}()
+	}
 
-	// 注册当前IP查询路由
-	mux.HandleFunc("GET /", ipHandler.HandleCurrentIP)
-	mux.HandleFunc("OPTIONS /", ipHandler.HandleCurrentIP)
-	// 注册当前IP查询路由
-	mux.HandleFunc("GET /ip", ipHandler.HandleCurrentIP)
-	mux.HandleFunc("OPTIONS /ip", ipHandler.HandleCurrentIP)
+	// DNS服务通过TXT记录提供查询
+	if cfg := &config.GetInstance().DNS; cfg.Enabled {
+		go func() {
+			if err := dnsserver.ListenAndServe(cfg, service.GetInstance()); err != nil {
+				logger.Fatal("DNS服务启动失败: %v", err)
+			}
+		}()
+	}
 
-	// 注册指定IP查询路由
-	mux.HandleFunc("GET /ip/{ip}", ipHandler.HandleQueryIP)
-	mux.HandleFunc("OPTIONS /ip/{ip}", ipHandler.HandleQueryIP)
+	// whois服务与HTTP查询接口共享速率限制
+	if cfg := &config.GetInstance().Whois; cfg.Enabled {
+		go func() {
+			if err := whois.ListenAndServe(cfg); err != nil {
+				logger.Fatal("whois服务启动失败: %v", err)
+			}
+		}()
+	}
 
-	// 启动服务器时使用 corsHandler 而不是 mux
-	addr := ":8080"
-	logger.Info("服务器启动在 %s", addr)
-	if err := http.ListenAndServe(addr, corsHandler); err != nil {
+	// 每个监听按配置注册各自的路由组
+	if err := server.ListenAndServe(config.GetInstance().Server.ListenerConfigs(), api.NewRouter); err != nil {
 		logger.Fatal("服务器启动失败: %v", err)
 	}
 }
@@ -2,6 +2,23 @@ module ip-geo
 
 go 1.23.4
 
-require github.com/oschwald/maxminddb-golang v1.13.1
+require (
+	github.com/gorilla/websocket v1.5.3
+	github.com/miekg/dns v1.1.63
+	github.com/oschwald/maxminddb-golang v1.13.1
+	github.com/ulikunitz/xz v0.5.15
+	golang.org/x/net v0.33.0
+	golang.org/x/time v0.8.0
+	google.golang.org/grpc v1.70.0
+	google.golang.org/protobuf v1.35.2
+	gopkg.in/yaml.v3 v3.0.1
+)
 
-require golang.org/x/sys v0.28.0 // indirect
+require (
+	golang.org/x/mod v0.18.0 // indirect
+	golang.org/x/sync v0.10.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+	golang.org/x/tools v0.22.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20241202173237-19429a94021a // indirect
+)
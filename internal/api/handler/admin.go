@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"ip-geo/internal/api/response"
+	"ip-geo/internal/config"
+	"ip-geo/internal/downloader"
+	"ip-geo/internal/logger"
+	"ip-geo/internal/mmdbdiff"
+	"ip-geo/internal/service"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// AdminHandler 处理管理接口请求
+type AdminHandler struct {
+	cfg       *config.Config
+	ipService *service.IPService
+}
+
+// NewAdminHandler 创建新的AdminHandler实例
+func NewAdminHandler() *AdminHandler {
+	return &AdminHandler{
+		cfg:       config.GetInstance(),
+		ipService: service.GetInstance(),
+	}
+}
+
+// HandleListDatabases 列出所有已配置数据库的状态
+func (h *AdminHandler) HandleListDatabases(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.ipService.DatabaseStatuses())
+}
+
+// HandleGetDatabase 返回指定数据库的状态
+func (h *AdminHandler) HandleGetDatabase(w http.ResponseWriter, r *http.Request) {
+	status, err := h.ipService.DatabaseStatus(r.PathValue("name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// HandleRefreshDatabase 重新下载并加载指定数据库
+func (h *AdminHandler) HandleRefreshDatabase(w http.ResponseWriter, r *http.Request) {
+	h.handleDatabaseAction(w, r.PathValue("name"), h.ipService.RefreshDatabase)
+}
+
+// HandleRollbackDatabase 将指定数据库回滚到上一个版本，指定version参数时切换到该版本
+func (h *AdminHandler) HandleRollbackDatabase(w http.ResponseWriter, r *http.Request) {
+	if id := r.URL.Query().Get("version"); id != "" {
+		h.handleDatabaseAction(w, r.PathValue("name"), func(name string) error {
+			return h.ipService.ActivateDatabaseVersion(name, id)
+		})
+		return
+	}
+	h.handleDatabaseAction(w, r.PathValue("name"), h.ipService.RollbackDatabase)
+}
+
+// HandleListVersions 列出指定数据库保留的历史版本
+func (h *AdminHandler) HandleListVersions(w http.ResponseWriter, r *http.Request) {
+	manifest, err := h.ipService.DatabaseVersions(r.PathValue("name"))
+	if err == service.ErrUnknownDatabase {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Error("读取版本清单失败: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, manifest)
+}
+
+// HandleRefreshAll 依次重新下载并加载所有数据库，返回每个数据库的结果
+func (h *AdminHandler) HandleRefreshAll(w http.ResponseWriter, r *http.Request) {
+	var results []*response.DatabaseResult
+	code := http.StatusOK
+	for _, name := range h.cfg.DatabaseNames() {
+		result := &response.DatabaseResult{Name: name}
+		if err := h.ipService.RefreshDatabase(name); err != nil {
+			logger.Error("刷新数据库 %s 失败: %v", name, err)
+			result.Error = err.Error()
+			code = http.StatusInternalServerError
+		}
+		result.Status, _ = h.ipService.DatabaseStatus(name)
+		results = append(results, result)
+	}
+	writeJSON(w, code, results)
+}
+
+// handleDatabaseAction 对单个数据库执行操作并返回操作后的状态
+func (h *AdminHandler) handleDatabaseAction(w http.ResponseWriter, name string, action func(string) error) {
+	if err := action(name); err != nil {
+		if err == service.ErrUnknownDatabase {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		logger.Error("数据库 %s 操作失败: %v", name, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	status, _ := h.ipService.DatabaseStatus(name)
+	writeJSON(w, http.StatusOK, status)
+}
+
+// HandleDiff 比较数据库的两个版本
+//
+// 参数db为数据源名称。old和new为与该数据库位于同一目录下的文件名，old_version和new_version为
+// 版本存储中的版本ID，同一侧只能指定其中一个，new一侧都为空时使用当前数据库文件。
+// format=csv时返回完整的网段差异，否则返回JSON格式的汇总。
+func (h *AdminHandler) HandleDiff(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	database, ok := h.cfg.Database(query.Get("db"))
+	if !ok {
+		http.Error(w, "未知的数据库", http.StatusBadRequest)
+		return
+	}
+	current := database.Path
+
+	oldPath, err := diffPath(current, query.Get("old"), query.Get("old_version"))
+	if err != nil {
+		writeDiffError(w, err)
+		return
+	}
+	newPath := current
+	if query.Get("new") != "" || query.Get("new_version") != "" {
+		if newPath, err = diffPath(current, query.Get("new"), query.Get("new_version")); err != nil {
+			writeDiffError(w, err)
+			return
+		}
+	}
+
+	oldReader, err := maxminddb.Open(oldPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("打开数据库失败: %v", err), http.StatusNotFound)
+		return
+	}
+	defer oldReader.Close()
+
+	newReader, err := maxminddb.Open(newPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("打开数据库失败: %v", err), http.StatusNotFound)
+		return
+	}
+	defer newReader.Close()
+
+	opts := mmdbdiff.Options{Schema: database.Schema}
+	logger.Info("比较数据库 %s 与 %s", oldPath, newPath)
+
+	if query.Get("format") == "csv" {
+		// 边比较边输出，出错时响应已经开始，只能记录日志
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		csvWriter := mmdbdiff.NewCSVWriter(w)
+		opts.OnChange = csvWriter.Write
+		if _, err := mmdbdiff.Diff(oldReader, newReader, opts); err != nil {
+			logger.Error("比较数据库失败: %v", err)
+			return
+		}
+		if err := csvWriter.Flush(); err != nil {
+			logger.Error("输出差异失败: %v", err)
+		}
+		return
+	}
+
+	report, err := mmdbdiff.Diff(oldReader, newReader, opts)
+	if err != nil {
+		logger.Error("比较数据库失败: %v", err)
+		http.Error(w, fmt.Sprintf("比较数据库失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// writeJSON 以JSON格式写入响应
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Error("编码响应失败: %v", err)
+	}
+}
+
+// errVersionNotFound 版本存储中没有指定的版本
+type errVersionNotFound struct {
+	err error
+}
+
+func (e *errVersionNotFound) Error() string { return e.err.Error() }
+
+// diffPath 根据文件名或版本ID返回参与比较的文件路径，两者只能指定一个
+func diffPath(current, name, version string) (string, error) {
+	if name != "" && version != "" {
+		return "", fmt.Errorf("文件名和版本不能同时指定")
+	}
+	if name == "" && version == "" {
+		return "", fmt.Errorf("需要指定文件名或版本")
+	}
+	if version == "" {
+		return siblingPath(current, name)
+	}
+	path, err := downloader.Versions(current).VersionPath(version)
+	if err != nil {
+		return "", &errVersionNotFound{err: err}
+	}
+	return path, nil
+}
+
+// writeDiffError 返回比较参数的错误，版本不存在时返回404
+func writeDiffError(w http.ResponseWriter, err error) {
+	var notFound *errVersionNotFound
+	if errors.As(err, &notFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+// siblingPath 返回与数据库位于同一目录下的文件路径，不允许包含目录
+func siblingPath(current, name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return "", fmt.Errorf("无效的文件名: %s", strconv.Quote(name))
+	}
+	return filepath.Join(filepath.Dir(current), name), nil
+}
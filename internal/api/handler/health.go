@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+
+	"ip-geo/internal/api/response"
+	"ip-geo/internal/service"
+)
+
+// HealthHandler 处理存活和就绪检查
+type HealthHandler struct {
+	ipService *service.IPService
+}
+
+// NewHealthHandler 创建新的HealthHandler实例
+func NewHealthHandler() *HealthHandler {
+	return &HealthHandler{
+		ipService: service.GetInstance(),
+	}
+}
+
+// HandleHealth 存活检查，进程能处理请求即返回200
+func (h *HealthHandler) HandleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(response.StatusOK))
+}
+
+// HandleReady 就绪检查，没有可用的地理位置或ASN数据库时返回503
+//
+// 部分数据库不可用时仍返回200，状态为degraded并列出不可用的数据源。
+func (h *HealthHandler) HandleReady(w http.ResponseWriter, r *http.Request) {
+	readiness := &response.Readiness{
+		Status:             response.StatusOK,
+		SourcesUnavailable: h.ipService.UnavailableSources(),
+	}
+
+	code := http.StatusOK
+	switch {
+	case !h.ipService.Ready():
+		readiness.Status = response.StatusUnavailable
+		code = http.StatusServiceUnavailable
+	case len(readiness.SourcesUnavailable) > 0:
+		readiness.Status = response.StatusDegraded
+	}
+	writeJSON(w, code, readiness)
+}
@@ -18,7 +18,7 @@ type IPHandler struct {
 // NewIPHandler 创建新的IPHandler实例
 func NewIPHandler() *IPHandler {
 	return &IPHandler{
-		ipService: service.NewIPService(),
+		ipService: service.GetInstance(),
 	}
 }
 
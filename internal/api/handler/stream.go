@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"ip-geo/internal/api/response"
+	"ip-geo/internal/config"
+	"ip-geo/internal/logger"
+	"ip-geo/internal/ratelimit"
+	"ip-geo/internal/service"
+
+	"github.com/gorilla/websocket"
+)
+
+// 流式查询的默认限制
+const (
+	defaultStreamConnections = 100
+	defaultStreamWindow      = 100
+	defaultStreamIdleTimeout = 60 * time.Second
+	// maxStreamLine 单行的最大长度，IPv6地址最长45个字符
+	maxStreamLine = 256
+	// maxWebSocketMessage 单个WebSocket消息的最大长度，一个消息可以包含多行
+	maxWebSocketMessage = 64 << 10
+)
+
+// errStreamLimit 连接查询的IP数达到上限
+var errStreamLimit = errors.New("查询的IP数达到连接上限")
+
+// StreamHandler 处理流式查询，客户端持续发送IP，服务端按接收顺序逐个返回结果
+//
+// 读取和写出在不同的goroutine中进行，已读取但未写出的IP达到窗口大小时暂停读取，
+// 客户端不读取结果时由TCP流量控制限制发送速度。超过速率限制时等待令牌，同样会暂停读取。
+type StreamHandler struct {
+	ipService   *service.IPService
+	limiter     *ratelimit.Limiter
+	maxIPs      int
+	window      int
+	idleTimeout time.Duration
+	// conns 当前连接数的信号量
+	conns    chan struct{}
+	upgrader websocket.Upgrader
+}
+
+// NewStreamHandler 创建新的StreamHandler实例
+func NewStreamHandler() *StreamHandler {
+	cfg := config.GetInstance().Stream
+	h := &StreamHandler{
+		ipService:   service.GetInstance(),
+		limiter:     ratelimit.GetInstance(),
+		maxIPs:      cfg.MaxIPs,
+		window:      cfg.Window,
+		idleTimeout: time.Duration(cfg.IdleTimeout) * time.Second,
+		upgrader: websocket.Upgrader{
+			// 查询接口允许所有来源，与CORS设置一致
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+	maxConns := cfg.MaxConnections
+	if maxConns <= 0 {
+		maxConns = defaultStreamConnections
+	}
+	h.conns = make(chan struct{}, maxConns)
+	if h.window <= 0 {
+		h.window = defaultStreamWindow
+	}
+	if h.idleTimeout <= 0 {
+		h.idleTimeout = defaultStreamIdleTimeout
+	}
+	return h
+}
+
+// HandleNDJSON 处理POST /ip/stream，请求体为每行一个IP，响应为每行一个JSON结果
+//
+// 查询成功的行为IP信息，失败的行为包含ip和error的对象。
+func (h *StreamHandler) HandleNDJSON(w http.ResponseWriter, r *http.Request) {
+	if !h.acquire() {
+		http.Error(w, "流式查询连接数已达上限", http.StatusServiceUnavailable)
+		return
+	}
+	defer h.release()
+
+	rc := http.NewResponseController(w)
+	// HTTP/1.1默认在开始写响应后不能再读取请求体，HTTP/2不需要设置
+	if err := rc.EnableFullDuplex(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		logger.Warn("启用全双工失败: %v", err)
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// 响应头随第一个结果写出，先写响应头会导致带Expect: 100-continue的请求无法读取请求体
+
+	deadline := &readDeadline{set: rc.SetReadDeadline}
+	read := func(send func(string) bool) error {
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(make([]byte, maxStreamLine), maxStreamLine)
+		for {
+			deadline.extend(h.idleTimeout)
+			if !scanner.Scan() {
+				break
+			}
+			if !sendLine(scanner.Text(), send) {
+				return nil
+			}
+		}
+		return scanner.Err()
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	write := func(v any, more bool) error {
+		rc.SetWriteDeadline(time.Now().Add(h.idleTimeout))
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		// 还有待写出的结果时合并写出
+		if more {
+			return nil
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	if err := h.pipeline(r.Context(), ratelimit.ClientKey(r.RemoteAddr), read, write, deadline.stop); err != nil {
+		logger.Debug("流式查询连接结束: %v", err)
+	}
+}
+
+// HandleWebSocket 处理GET /ip/stream的WebSocket连接，语义与HandleNDJSON相同
+//
+// 每个文本消息包含一个或多个换行分隔的IP，每个结果作为一个文本消息返回。
+func (h *StreamHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	if !h.acquire() {
+		http.Error(w, "流式查询连接数已达上限", http.StatusServiceUnavailable)
+		return
+	}
+	defer h.release()
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade已经返回了错误响应
+		logger.Debug("WebSocket握手失败: %v", err)
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(maxWebSocketMessage)
+
+	deadline := &readDeadline{set: conn.SetReadDeadline}
+	read := func(send func(string) bool) error {
+		for {
+			deadline.extend(h.idleTimeout)
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					return nil
+				}
+				return err
+			}
+			for _, line := range strings.Split(string(data), "\n") {
+				if !sendLine(line, send) {
+					return nil
+				}
+			}
+		}
+	}
+
+	write := func(v any, more bool) error {
+		conn.SetWriteDeadline(time.Now().Add(h.idleTimeout))
+		return conn.WriteJSON(v)
+	}
+
+	err = h.pipeline(r.Context(), ratelimit.ClientKey(r.RemoteAddr), read, write, deadline.stop)
+	closeCode, reason := websocket.CloseNormalClosure, ""
+	if err != nil {
+		logger.Debug("WebSocket流式查询连接结束: %v", err)
+		closeCode, reason = websocket.ClosePolicyViolation, err.Error()
+	}
+	conn.SetWriteDeadline(time.Now().Add(time.Second))
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, reason))
+}
+
+// pipeline 在单独的goroutine中运行read读取IP，在当前goroutine中查询并调用write写出结果
+//
+// read通过send提交IP，send返回false表示写出已经停止，read应当返回。
+// 每个IP查询前等待key的速率限制，超过空闲超时仍无法查询时结束。
+// 读取出错或查询数达到上限时写出一条连接级别的错误后返回该错误。
+// 返回前调用stopRead使阻塞的读取立即返回，并等待read结束，处理函数返回后不能再读取请求。
+func (h *StreamHandler) pipeline(ctx context.Context, key string, read func(send func(string) bool) error, write func(v any, more bool) error, stopRead func()) error {
+	ips := make(chan string, h.window)
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		stopRead()
+		// 丢弃未处理的IP，ips关闭时read已经返回
+		for range ips {
+		}
+	}()
+
+	var readErr error
+	go func() {
+		defer close(ips)
+		readErr = read(func(ip string) bool {
+			select {
+			case ips <- ip:
+				return true
+			case <-done:
+				return false
+			}
+		})
+	}()
+
+	count := 0
+	for ip := range ips {
+		if h.maxIPs > 0 && count >= h.maxIPs {
+			write(&response.StreamError{Error: errStreamLimit.Error()}, false)
+			return errStreamLimit
+		}
+		if err := h.wait(ctx, key); err != nil {
+			write(&response.StreamError{Error: err.Error()}, false)
+			return err
+		}
+		count++
+		if err := write(h.lookup(ip), len(ips) > 0); err != nil {
+			return err
+		}
+	}
+
+	// ips关闭后readErr已经设置
+	if readErr != nil && readErr != io.EOF {
+		err := streamReadError(readErr, h.idleTimeout)
+		write(&response.StreamError{Error: err.Error()}, false)
+		return err
+	}
+	return nil
+}
+
+// wait 等待速率限制的令牌，最多等待空闲超时时间
+func (h *StreamHandler) wait(ctx context.Context, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, h.idleTimeout)
+	defer cancel()
+	if err := h.limiter.Wait(ctx, key); err != nil {
+		return fmt.Errorf("查询过于频繁")
+	}
+	return nil
+}
+
+// lookup 查询单个IP，返回IP信息或错误
+func (h *StreamHandler) lookup(ip string) any {
+	resp, err := h.ipService.LookupIP(ip)
+	if err != nil {
+		return &response.StreamError{IP: ip, Error: err.Error()}
+	}
+	return resp
+}
+
+// acquire 占用一个连接名额，达到上限时返回false
+func (h *StreamHandler) acquire() bool {
+	select {
+	case h.conns <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release 释放连接名额
+func (h *StreamHandler) release() {
+	<-h.conns
+}
+
+// readDeadline 读取请求的超时时间，停止后不再延长
+type readDeadline struct {
+	mu      sync.Mutex
+	stopped bool
+	set     func(time.Time) error
+}
+
+// extend 将超时时间延长到timeout之后
+func (d *readDeadline) extend(timeout time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.stopped {
+		d.set(time.Now().Add(timeout))
+	}
+}
+
+// stop 使正在进行和之后的读取立即超时
+func (d *readDeadline) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopped = true
+	d.set(time.Now())
+}
+
+// sendLine 提交一行中的IP，忽略空行
+func sendLine(line string, send func(string) bool) bool {
+	ip := strings.TrimSpace(line)
+	if ip == "" {
+		return true
+	}
+	return send(ip)
+}
+
+// streamReadError 返回读取请求失败时发送给客户端的错误
+func streamReadError(err error, idleTimeout time.Duration) error {
+	switch {
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		return fmt.Errorf("超过 %v 没有收到数据", idleTimeout)
+	case errors.Is(err, bufio.ErrTooLong), errors.Is(err, websocket.ErrReadLimit):
+		return fmt.Errorf("单行或单个消息过长")
+	}
+	return fmt.Errorf("读取请求失败: %v", err)
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ip-geo/internal/api/response"
+	"ip-geo/internal/ratelimit"
+	"ip-geo/internal/service"
+)
+
+// trackedBody 记录处理函数返回时和返回后对请求体的读取
+type trackedBody struct {
+	io.ReadCloser
+	returned  atomic.Bool
+	reading   atomic.Int32
+	lateReads atomic.Int32
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	if b.returned.Load() {
+		b.lateReads.Add(1)
+	}
+	b.reading.Add(1)
+	defer b.reading.Add(-1)
+	return b.ReadCloser.Read(p)
+}
+
+func newTestStreamHandler(maxIPs int) *StreamHandler {
+	return &StreamHandler{
+		ipService:   service.NewIPServiceWithProviders(nil, service.DefaultMergePolicy),
+		limiter:     ratelimit.New(0, 0),
+		maxIPs:      maxIPs,
+		window:      defaultStreamWindow,
+		idleTimeout: 5 * time.Second,
+		conns:       make(chan struct{}, 1),
+	}
+}
+
+// TestStreamMaxIPsWithOpenBody 客户端没有结束请求体时达到查询上限，
+// 处理函数应当结束响应并释放连接名额，返回后不再读取请求体
+func TestStreamMaxIPsWithOpenBody(t *testing.T) {
+	const maxIPs = 3
+	h := newTestStreamHandler(maxIPs)
+
+	body := &trackedBody{}
+	var readingAtReturn atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body.ReadCloser = r.Body
+		r.Body = body
+		h.HandleNDJSON(w, r)
+		readingAtReturn.Store(body.reading.Load())
+		body.returned.Store(true)
+	}))
+	defer srv.Close()
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	go func() {
+		for i := 0; i <= maxIPs; i++ {
+			fmt.Fprintf(pw, "192.0.2.%d\n", i)
+		}
+	}()
+
+	resp, err := http.Post(srv.URL, "text/plain", pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// 请求体保持打开，响应应当在连接级别的错误后结束
+	done := make(chan []string)
+	go func() {
+		var lines []string
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		done <- lines
+	}()
+	var lines []string
+	select {
+	case lines = <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("response did not end after reaching max_ips")
+	}
+
+	if len(lines) != maxIPs+1 {
+		t.Fatalf("got %d lines, want %d: %q", len(lines), maxIPs+1, lines)
+	}
+	var last response.StreamError
+	if err := json.Unmarshal([]byte(lines[maxIPs]), &last); err != nil {
+		t.Fatal(err)
+	}
+	if last.IP != "" || last.Error != errStreamLimit.Error() {
+		t.Errorf("last line = %s, want connection error %q", lines[maxIPs], errStreamLimit)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for !body.returned.Load() || len(h.conns) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("handler returned = %v, connections = %d", body.returned.Load(), len(h.conns))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := readingAtReturn.Load(); n != 0 {
+		t.Errorf("%d reads of the request body in progress when handler returned", n)
+	}
+
+	// 处理函数返回后继续发送数据，不应再有读取请求体的goroutine
+	go fmt.Fprintf(pw, "192.0.2.100\n")
+	time.Sleep(100 * time.Millisecond)
+	if n := body.lateReads.Load(); n != 0 {
+		t.Errorf("request body read %d times after handler returned", n)
+	}
+}
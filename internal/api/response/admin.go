@@ -0,0 +1,38 @@
+package response
+
+import "time"
+
+// DatabaseStatus 表示数据库的状态
+type DatabaseStatus struct {
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	Path     string `json:"path"`
+	Required bool   `json:"required"`
+	Loaded   bool   `json:"loaded"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+	// 以下元数据来自正在使用的数据库
+	DatabaseType string    `json:"database_type,omitempty"`
+	BuildEpoch   uint      `json:"build_epoch,omitempty"`
+	BuildTime    time.Time `json:"build_time,omitempty"`
+	Languages    []string  `json:"languages,omitempty"`
+	// LoadMode 加载方式(mmap/memory/embed)
+	LoadMode string `json:"load_mode"`
+	// Memory 数据库占用的字节数，mmap方式为映射的文件大小
+	Memory int64 `json:"memory,omitempty"`
+	// DownloadedAt 最近一次下载时间，本进程没有下载过时为文件修改时间
+	DownloadedAt *time.Time `json:"downloaded_at,omitempty"`
+	SourceURL    string     `json:"source_url,omitempty"`
+	// Version 当前版本，数据库不受版本管理时为空
+	Version string `json:"version,omitempty"`
+	// Versions 保留的版本数
+	Versions int    `json:"versions"`
+	Error    string `json:"error,omitempty"`
+}
+
+// DatabaseResult 表示对单个数据库执行操作的结果
+type DatabaseResult struct {
+	Name   string          `json:"name"`
+	Error  string          `json:"error,omitempty"`
+	Status *DatabaseStatus `json:"status,omitempty"`
+}
@@ -0,0 +1,16 @@
+package response
+
+// 服务状态
+const (
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded"
+	StatusUnavailable = "unavailable"
+)
+
+// Readiness 表示服务的就绪状态
+type Readiness struct {
+	// Status ok表示所有数据库可用，degraded表示部分数据库不可用，unavailable表示无法处理查询
+	Status string `json:"status"`
+	// SourcesUnavailable 数据库不可用的数据源
+	SourcesUnavailable []string `json:"sources_unavailable,omitempty"`
+}
@@ -38,6 +38,15 @@ type IPResponse struct {
 		Name string `json:"name"`
 		Type string `json:"type"`
 	} `json:"isp"`
+	Security Security `json:"security"`
+	// Tags 覆盖数据中的自定义标签
+	Tags []string `json:"tags,omitempty"`
+	// Overrides 来自本地覆盖数据的字段
+	Overrides []string `json:"overrides,omitempty"`
+	// Fallback 来自内嵌国家级数据库的字段，精度低于完整的数据库
+	Fallback []string `json:"fallback,omitempty"`
+	// SourcesUnavailable 数据库不可用的数据源，这些数据源提供的字段可能缺失或来自其他数据源
+	SourcesUnavailable []string `json:"sources_unavailable,omitempty"`
 }
 
 // Region 表示地区信息
@@ -50,3 +59,21 @@ type Region struct {
 type City struct {
 	Name string `json:"name"`
 }
+
+// Security 表示匿名网络检测信息
+type Security struct {
+	IsAnonymous        bool `json:"is_anonymous"`
+	IsAnonymousVPN     bool `json:"is_anonymous_vpn"`
+	IsHostingProvider  bool `json:"is_hosting_provider"`
+	IsPublicProxy      bool `json:"is_public_proxy"`
+	IsResidentialProxy bool `json:"is_residential_proxy"`
+	IsTorExitNode      bool `json:"is_tor_exit_node"`
+	// Sources 每个标记的数据来源
+	Sources map[string][]string `json:"sources,omitempty"`
+}
+
+// StreamError 表示流式查询中的错误，IP为空时为连接级别的错误，之后服务端关闭连接
+type StreamError struct {
+	IP    string `json:"ip,omitempty"`
+	Error string `json:"error"`
+}
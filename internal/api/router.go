@@ -0,0 +1,109 @@
+// Package api 按路由组注册HTTP接口
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"ip-geo/internal/api/handler"
+	"ip-geo/internal/config"
+	"ip-geo/internal/logger"
+	"ip-geo/internal/middleware"
+	"ip-geo/internal/ratelimit"
+)
+
+// 路由组
+const (
+	// RoutesIP IP查询接口
+	RoutesIP = "ip"
+	// RoutesHealth 存活和就绪检查
+	RoutesHealth = "health"
+	// RoutesAdmin 管理接口，需要启用并配置访问令牌
+	RoutesAdmin = "admin"
+)
+
+// AllRoutes 所有路由组
+var AllRoutes = []string{RoutesIP, RoutesHealth, RoutesAdmin}
+
+// NewRouter 创建注册了指定路由组的处理器，groups为空时注册所有路由组
+func NewRouter(groups []string) (http.Handler, error) {
+	if len(groups) == 0 {
+		groups = AllRoutes
+	}
+
+	// 创建路由
+	mux := http.NewServeMux()
+	for _, group := range groups {
+		switch group {
+		case RoutesIP:
+			registerIPRoutes(mux)
+		case RoutesHealth:
+			registerHealthRoutes(mux)
+		case RoutesAdmin:
+			registerAdminRoutes(mux)
+		default:
+			return nil, fmt.Errorf("未知的路由组: %s", group)
+		}
+	}
+
+	// 包装所有处理器以支持CORS
+	return middleware.CORS(mux), nil
+}
+
+// registerIPRoutes 注册IP查询路由
+func registerIPRoutes(mux *http.ServeMux) {
+	ipHandler := handler.NewIPHandler()
+	// 查询接口按客户端地址限制速率，与whois共享限额
+	limiter := ratelimit.GetInstance()
+	limited := func(h http.HandlerFunc) http.Handler {
+		return middleware.RateLimit(limiter, h)
+	}
+
+	// 注册当前IP查询路由
+	mux.Handle("GET /", limited(ipHandler.HandleCurrentIP))
+	mux.HandleFunc("OPTIONS /", ipHandler.HandleCurrentIP)
+	// 注册当前IP查询路由
+	mux.Handle("GET /ip", limited(ipHandler.HandleCurrentIP))
+	mux.HandleFunc("OPTIONS /ip", ipHandler.HandleCurrentIP)
+
+	// 注册指定IP查询路由
+	mux.Handle("GET /ip/{ip}", limited(ipHandler.HandleQueryIP))
+	mux.HandleFunc("OPTIONS /ip/{ip}", ipHandler.HandleQueryIP)
+
+	// 注册流式查询路由，POST为NDJSON，GET为WebSocket，每个IP消耗一次限额
+	streamHandler := handler.NewStreamHandler()
+	mux.HandleFunc("POST /ip/stream", streamHandler.HandleNDJSON)
+	mux.HandleFunc("GET /ip/stream", streamHandler.HandleWebSocket)
+}
+
+// registerHealthRoutes 注册存活和就绪检查路由
+func registerHealthRoutes(mux *http.ServeMux) {
+	healthHandler := handler.NewHealthHandler()
+	mux.HandleFunc("GET /health", healthHandler.HandleHealth)
+	mux.HandleFunc("GET /ready", healthHandler.HandleReady)
+}
+
+// registerAdminRoutes 注册管理接口，所有请求需携带访问令牌
+func registerAdminRoutes(mux *http.ServeMux) {
+	admin := config.GetInstance().Admin
+	if !admin.Enabled {
+		return
+	}
+	if admin.Token == "" {
+		logger.Error("未配置管理接口访问令牌，不启用管理接口")
+		return
+	}
+
+	adminHandler := handler.NewAdminHandler()
+	adminRoute := func(pattern string, h http.HandlerFunc) {
+		mux.Handle(pattern, middleware.BearerAuth(admin.Token, h))
+	}
+	adminRoute("GET /admin/databases", adminHandler.HandleListDatabases)
+	adminRoute("GET /admin/databases/{name}", adminHandler.HandleGetDatabase)
+	adminRoute("POST /admin/databases/refresh", adminHandler.HandleRefreshAll)
+	adminRoute("POST /admin/databases/{name}/refresh", adminHandler.HandleRefreshDatabase)
+	adminRoute("POST /admin/databases/{name}/rollback", adminHandler.HandleRollbackDatabase)
+	adminRoute("GET /admin/databases/{name}/versions", adminHandler.HandleListVersions)
+	adminRoute("GET /admin/diff", adminHandler.HandleDiff)
+	logger.Info("已启用管理接口")
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"sync"
 
@@ -10,15 +11,272 @@ import (
 
 // Config 配置结构
 type Config struct {
-	// 数据库配置
+	// 数据库列表，未配置时根据asn_db_path等路径字段生成
+	Databases []Database `json:"databases"`
+
+	// 数据库的默认加载方式(mmap/memory/embed)，默认为mmap
+	LoadMode string `json:"load_mode"`
+
+	// 数据库配置，未配置databases时使用
 	ASNDB   string `json:"asn_db_path"`
 	CityDB  string `json:"city_db_path"`
 	GeoCNDB string `json:"geo_cn_db_path"`
 
+	// 本地覆盖文件路径，支持YAML/JSON/CSV，为空时不启用
+	OverridesPath string `json:"overrides_path"`
+
+	// 由cmd/mmdb-build编译生成的自定义数据库路径，为空时不启用
+	CustomDB string `json:"custom_db_path"`
+
+	// 每个数据库保留的历史版本数，默认为5
+	SnapshotKeep int `json:"snapshot_keep"`
+
+	// 数据库记录格式，键为数据库名称，为空时根据数据库类型自动识别
+	Schemas map[string]string `json:"schemas"`
+
+	// 字段合并策略，键为字段名，值为按优先级排列的数据源名称
+	MergePolicy map[string][]string `json:"merge_policy"`
+
+	// 安全检测配置
+	Security struct {
+		// GeoIP2-Anonymous-IP格式数据库路径，为空时不启用
+		AnonymousIPDB string `json:"anonymous_ip_db_path"`
+		// Tor出口节点列表文件路径，为空时不启用
+		TorExitList string `json:"tor_exit_list_path"`
+		// 托管服务商ASN列表，未配置时使用asn.HostingASNs
+		HostingASNs []int `json:"hosting_asns"`
+	} `json:"security"`
+
+	// 数据库下载配置
+	Download struct {
+		// 要求下载地址提供.sha256校验文件，为false时没有校验文件则跳过校验和检查
+		RequireChecksum bool `json:"require_checksum"`
+		// 允许构建时间早于当前数据库的文件替换当前数据库
+		AllowOlder bool `json:"allow_older"`
+		// 下载失败时的最大尝试次数，默认为5
+		MaxRetries int `json:"max_retries"`
+		// 超过该秒数没有收到数据时中断下载，默认为60
+		IdleTimeout int `json:"idle_timeout"`
+		// 下载使用的代理，支持http、https和socks5，为空时使用环境变量HTTP_PROXY/HTTPS_PROXY
+		Proxy string `json:"proxy"`
+		// 不使用代理的主机，逗号分隔，对配置的代理和环境变量中的代理都生效，为空时使用环境变量NO_PROXY
+		NoProxy string `json:"no_proxy"`
+		// MaxMind账号，未配置时读取环境变量MAXMIND_ACCOUNT_ID和MAXMIND_LICENSE_KEY
+		MaxMind struct {
+			AccountID  int    `json:"account_id"`
+			LicenseKey string `json:"license_key"`
+			// 下载接口地址，默认为https://download.maxmind.com
+			BaseURL string `json:"base_url"`
+		} `json:"maxmind"`
+		// 每个数据库的下载来源，键为数据库名称，数据库配置中的download优先
+		Sources map[string]DownloadSource `json:"sources"`
+	} `json:"download"`
+
+	// 管理接口配置
+	Admin struct {
+		// 是否注册/admin接口
+		Enabled bool `json:"enabled"`
+		// 访问令牌，请求需携带Authorization: Bearer <token>，为空时不注册管理接口
+		Token string `json:"token"`
+	} `json:"admin"`
+
 	// 服务器配置
-	Server struct {
-		Port int `json:"port"`
-	} `json:"server"`
+	Server ServerConfig `json:"server"`
+
+	// 查询速率限制，HTTP查询接口、流式查询和whois按客户端地址共享同一个限额
+	RateLimit struct {
+		// 每个客户端每秒允许的查询数，0表示不限制
+		Rate float64 `json:"rate"`
+		// 允许的突发查询数，默认与rate相同
+		Burst int `json:"burst"`
+	} `json:"rate_limit"`
+
+	// 流式查询(/ip/stream)配置
+	Stream struct {
+		// 每个监听同时进行的流式查询连接数上限，默认为100
+		MaxConnections int `json:"max_connections"`
+		// 每个连接最多查询的IP数，0表示不限制
+		MaxIPs int `json:"max_ips"`
+		// 已读取但未写出结果的IP数上限，达到上限时暂停读取请求，默认为100
+		Window int `json:"window"`
+		// 超过该秒数没有收到IP或无法写出结果时关闭连接，默认为60
+		IdleTimeout int `json:"idle_timeout"`
+	} `json:"stream"`
+
+	// gRPC服务配置
+	GRPC GRPCConfig `json:"grpc"`
+
+	// DNS查询服务配置
+	DNS DNSConfig `json:"dns"`
+
+	// whois服务配置
+	Whois WhoisConfig `json:"whois"`
+}
+
+// ServerConfig HTTP服务器配置
+type ServerConfig struct {
+	// 监听端口，默认为8080
+	Port int `json:"port"`
+	// 监听地址，如127.0.0.1:8443，设置后忽略port
+	Addr string `json:"addr"`
+	// 不使用TLS时是否支持明文HTTP/2(h2c)，适用于反向代理使用HTTP/2连接后端
+	H2C bool `json:"h2c"`
+	// 禁用HTTP/2，只使用HTTP/1.1
+	DisableHTTP2 bool `json:"disable_http2"`
+	// TLS配置，cert_file和key_file都设置时启用
+	TLS TLSConfig `json:"tls"`
+	// 监听列表，设置后忽略以上字段
+	Listeners []ListenerConfig `json:"listeners"`
+}
+
+// ListenerConfig 监听配置
+type ListenerConfig struct {
+	// 名称，用于日志
+	Name string `json:"name"`
+	// 网络类型，tcp、unix或systemd，默认为tcp
+	Network string `json:"network"`
+	// tcp为监听地址，unix为套接字路径，systemd为套接字名称(FileDescriptorName)，为空时按顺序使用
+	Addr string `json:"addr"`
+	// unix套接字文件权限，如0660
+	Mode string `json:"mode"`
+	// 注册的路由组(ip/health/admin)，为空时注册所有路由
+	Routes []string `json:"routes"`
+	// 不使用TLS时是否支持明文HTTP/2(h2c)
+	H2C bool `json:"h2c"`
+	// 禁用HTTP/2，只使用HTTP/1.1
+	DisableHTTP2 bool `json:"disable_http2"`
+	// TLS配置，cert_file和key_file都设置时启用
+	TLS TLSConfig `json:"tls"`
+}
+
+// ListenerConfigs 返回所有监听配置，未配置listeners时根据port、addr和tls生成一个注册所有路由的TCP监听
+func (s *ServerConfig) ListenerConfigs() []ListenerConfig {
+	if len(s.Listeners) > 0 {
+		return s.Listeners
+	}
+	addr := s.Addr
+	if addr == "" {
+		addr = fmt.Sprintf(":%d", s.Port)
+	}
+	return []ListenerConfig{{
+		Name:         "default",
+		Network:      "tcp",
+		Addr:         addr,
+		H2C:          s.H2C,
+		DisableHTTP2: s.DisableHTTP2,
+		TLS:          s.TLS,
+	}}
+}
+
+// GRPCConfig gRPC服务配置
+type GRPCConfig struct {
+	// 是否启动gRPC服务
+	Enabled bool `json:"enabled"`
+	// 监听端口，默认为9090
+	Port int `json:"port"`
+	// 监听地址，如127.0.0.1:9090，设置后忽略port
+	Addr string `json:"addr"`
+	// BatchLookup一次最多查询的IP数，默认为1000
+	MaxBatchSize int `json:"max_batch_size"`
+	// TLS配置，cert_file和key_file都设置时启用，不支持redirect_addr
+	TLS TLSConfig `json:"tls"`
+}
+
+// ListenAddr 返回gRPC服务的监听地址
+func (g *GRPCConfig) ListenAddr() string {
+	if g.Addr != "" {
+		return g.Addr
+	}
+	return fmt.Sprintf(":%d", g.Port)
+}
+
+// DNSConfig DNS查询服务配置
+type DNSConfig struct {
+	// 是否启动DNS服务
+	Enabled bool `json:"enabled"`
+	// 监听地址，同时监听UDP和TCP，默认为:5353
+	Addr string `json:"addr"`
+	// 应答的区域，默认为geo.local
+	Zone string `json:"zone"`
+	// 查询结果的TTL秒数，默认为300
+	TTL int `json:"ttl"`
+}
+
+// WhoisConfig whois服务配置
+type WhoisConfig struct {
+	// 是否启动whois服务
+	Enabled bool `json:"enabled"`
+	// 监听地址，默认为:43
+	Addr string `json:"addr"`
+	// 同时处理的连接数上限，默认为100
+	MaxConnections int `json:"max_connections"`
+	// 批量模式下每个连接最多查询的IP数，默认为10000
+	MaxBulk int `json:"max_bulk"`
+	// 超过该秒数没有收到数据时关闭连接，默认为30
+	IdleTimeout int `json:"idle_timeout"`
+}
+
+// TLSConfig TLS配置
+type TLSConfig struct {
+	// PEM格式的证书链和私钥文件
+	CertFile string `json:"cert_file"`
+	KeyFile  string `json:"key_file"`
+	// 校验客户端证书的CA文件，设置后启用双向TLS
+	ClientCAFile string `json:"client_ca_file"`
+	// 客户端证书要求，require要求所有客户端提供证书，optional只校验提供的证书，默认为require
+	ClientAuth string `json:"client_auth"`
+	// 最低TLS版本(1.2/1.3)，默认为1.2
+	MinVersion string `json:"min_version"`
+	// 检查证书文件是否变化的间隔秒数，默认为60
+	ReloadInterval int `json:"reload_interval"`
+	// HTTP重定向到HTTPS的监听地址，如:80，为空时不启用
+	RedirectAddr string `json:"redirect_addr"`
+}
+
+// Enabled 判断是否启用TLS
+func (t *TLSConfig) Enabled() bool {
+	return t.CertFile != "" && t.KeyFile != ""
+}
+
+// Database 数据库配置
+type Database struct {
+	// 数据库名称，作为数据源名称和管理接口中的名称，不能重复
+	Name string `json:"name"`
+	// 数据库文件路径
+	Path string `json:"path"`
+	// 数据源角色(asn/city/geocn/anonymous_ip/custom)，决定默认记录格式和在合并策略中的位置，为空时与名称相同
+	Role string `json:"role"`
+	// 记录格式，为空时根据数据库类型自动识别
+	Schema string `json:"schema"`
+	// 下载来源，为空时使用内置的下载地址
+	Download *DownloadSource `json:"download"`
+	// 加载方式，mmap为内存映射文件，memory为读入内存，embed为使用编译时内嵌的文件，为空时使用load_mode
+	LoadMode string `json:"load_mode"`
+	// 必需的数据库不可用时服务不就绪
+	Required bool `json:"required"`
+	// 是否启用，默认为true
+	Enabled *bool `json:"enabled"`
+}
+
+// IsEnabled 判断数据库是否启用
+func (d *Database) IsEnabled() bool {
+	return d.Enabled == nil || *d.Enabled
+}
+
+// DownloadSource 数据库的下载来源，URL/Mirrors和EditionID二选一
+type DownloadSource struct {
+	// 下载地址，根据后缀识别压缩格式
+	URL string `json:"url"`
+	// 备用镜像地址，排在URL之后
+	Mirrors []string `json:"mirrors"`
+	// 镜像选择方式，sequential按顺序依次尝试，race同时请求所有镜像并优先使用最先响应的，默认为sequential
+	Strategy string `json:"strategy"`
+	// MaxMind数据库版本，如GeoLite2-City，设置后使用MaxMind账号从官方接口下载
+	EditionID string `json:"edition_id"`
+	// 下载文件格式(mmdb/gz/xz/zip/tar.gz/tar.xz)，为空时根据地址后缀识别
+	Format string `json:"format"`
+	// 从压缩包中提取的文件名模式，默认为*.mmdb，包含/时匹配完整路径
+	Member string `json:"member"`
 }
 
 var (
@@ -34,16 +292,134 @@ func GetInstance() *Config {
 			ASNDB:   "mmdb/GeoLite2-ASN.mmdb",
 			CityDB:  "mmdb/GeoIP2-City.mmdb",
 			GeoCNDB: "mmdb/GeoCN.mmdb",
-			Server: struct {
-				Port int `json:"port"`
-			}{
+			Server: ServerConfig{
 				Port: 8080,
 			},
+			GRPC: GRPCConfig{
+				Port:         9090,
+				MaxBatchSize: 1000,
+			},
+			DNS: DNSConfig{
+				Addr: ":5353",
+				Zone: "geo.local",
+				TTL:  300,
+			},
+			Whois: WhoisConfig{
+				Addr:           ":43",
+				MaxConnections: 100,
+				MaxBulk:        10000,
+				IdleTimeout:    30,
+			},
 		}
+		instance.normalize()
 	})
 	return instance
 }
 
+// Database 返回指定名称的已启用数据库，未配置或未启用时返回false
+func (c *Config) Database(name string) (*Database, bool) {
+	for i := range c.Databases {
+		d := &c.Databases[i]
+		if d.Name == name && d.IsEnabled() {
+			return d, true
+		}
+	}
+	return nil, false
+}
+
+// DatabasePath 根据数据源名称返回数据库文件路径，未配置时返回false
+func (c *Config) DatabasePath(name string) (string, bool) {
+	d, ok := c.Database(name)
+	if !ok {
+		return "", false
+	}
+	return d.Path, true
+}
+
+// EnabledDatabases 按配置顺序返回已启用的数据库
+func (c *Config) EnabledDatabases() []*Database {
+	var databases []*Database
+	for i := range c.Databases {
+		if d := &c.Databases[i]; d.IsEnabled() {
+			databases = append(databases, d)
+		}
+	}
+	return databases
+}
+
+// DatabaseNames 按配置顺序返回已启用的数据库名称
+func (c *Config) DatabaseNames() []string {
+	var names []string
+	for _, d := range c.EnabledDatabases() {
+		names = append(names, d.Name)
+	}
+	return names
+}
+
+// legacyDatabases 根据asn_db_path等路径字段生成数据库列表，路径为空的数据库不启用
+func (c *Config) legacyDatabases() []Database {
+	paths := []struct{ name, path string }{
+		{"asn", c.ASNDB},
+		{"city", c.CityDB},
+		{"geocn", c.GeoCNDB},
+		{"anonymous_ip", c.Security.AnonymousIPDB},
+		{"custom", c.CustomDB},
+	}
+	var databases []Database
+	for _, p := range paths {
+		if p.path != "" {
+			databases = append(databases, Database{Name: p.name, Path: p.path})
+		}
+	}
+	return databases
+}
+
+// normalize 补全数据库列表并检查配置
+//
+// 未配置databases时使用路径字段，数据库没有设置格式和下载来源时使用schemas和download.sources中的同名配置，
+// 没有设置加载方式时使用load_mode。
+func (c *Config) normalize() error {
+	if len(c.Databases) == 0 {
+		c.Databases = c.legacyDatabases()
+	}
+
+	names := make(map[string]bool, len(c.Databases))
+	for i := range c.Databases {
+		d := &c.Databases[i]
+		if d.Name == "" {
+			return fmt.Errorf("第 %d 个数据库没有设置名称", i+1)
+		}
+		if names[d.Name] {
+			return fmt.Errorf("数据库名称 %s 重复", d.Name)
+		}
+		names[d.Name] = true
+		if d.Path == "" {
+			return fmt.Errorf("数据库 %s 没有设置路径", d.Name)
+		}
+		if d.Role == "" {
+			d.Role = d.Name
+		}
+		if d.Schema == "" {
+			d.Schema = c.Schemas[d.Name]
+		}
+		if d.LoadMode == "" {
+			d.LoadMode = c.LoadMode
+		}
+		if d.Download == nil {
+			if src, ok := c.Download.Sources[d.Name]; ok {
+				d.Download = &src
+			}
+		}
+	}
+
+	for name := range c.Download.Sources {
+		if !names[name] {
+			logger.Warn("下载来源 %s 没有对应的数据库", name)
+		}
+	}
+	return nil
+}
+
 // LoadFromFile 从文件加载配置
 func (c *Config) LoadFromFile(filename string) error {
 	logger.Debug("从文件加载配置: %s", filename)
@@ -52,9 +428,17 @@ func (c *Config) LoadFromFile(filename string) error {
 		return err
 	}
 
+	// 数据库列表由文件中的配置重新生成
+	c.Databases = nil
 	if err := json.Unmarshal(data, c); err != nil {
 		return err
 	}
+	if err := c.normalize(); err != nil {
+		// 回退到路径字段，保证数据库列表可用
+		c.Databases = nil
+		c.normalize()
+		return fmt.Errorf("数据库配置无效: %v", err)
+	}
 
 	logger.Info("配置加载成功")
 	return nil
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ip-geo/internal/config"
+	"ip-geo/internal/embedded"
+	"ip-geo/internal/snapshot"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// 数据库的加载方式
+const (
+	// LoadModeMMap 内存映射文件，占用页缓存，文件被原地修改时查询结果不可预期
+	LoadModeMMap = "mmap"
+	// LoadModeMemory 将文件完整读入内存，之后文件的变化不影响正在使用的数据库
+	LoadModeMemory = "memory"
+	// LoadModeEmbed 使用编译时内嵌的文件，按数据库路径的文件名查找
+	LoadModeEmbed = "embed"
+)
+
+// Usage 数据库的加载方式和占用的内存
+type Usage struct {
+	Mode string
+	// Bytes 数据库占用的字节数，mmap方式为映射的文件大小
+	Bytes int64
+}
+
+// LoadMode 返回数据库的加载方式，未配置时为mmap
+func LoadMode(d *config.Database) string {
+	if d.LoadMode == "" {
+		return LoadModeMMap
+	}
+	return d.LoadMode
+}
+
+// Open 按数据库配置的加载方式打开数据库
+//
+// 受版本管理的数据库路径是当前版本的副本时，打开版本目录中的当前版本。
+func Open(d *config.Database) (*maxminddb.Reader, Usage, error) {
+	usage := Usage{Mode: LoadMode(d)}
+	path := snapshot.New(d.Path, 0).CurrentPath()
+
+	var data []byte
+	var err error
+	switch usage.Mode {
+	case LoadModeMMap:
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, usage, err
+		}
+		reader, err := maxminddb.Open(path)
+		if err != nil {
+			return nil, usage, err
+		}
+		usage.Bytes = info.Size()
+		return reader, usage, nil
+	case LoadModeMemory:
+		data, err = os.ReadFile(path)
+	case LoadModeEmbed:
+		data, err = embedded.ReadFile(filepath.Base(d.Path))
+	default:
+		return nil, usage, fmt.Errorf("数据库 %s 的加载方式 %s 无效", d.Name, usage.Mode)
+	}
+	if err != nil {
+		return nil, usage, err
+	}
+
+	reader, err := maxminddb.FromBytes(data)
+	if err != nil {
+		return nil, usage, err
+	}
+	usage.Bytes = int64(len(data))
+	return reader, usage, nil
+}
@@ -11,10 +11,13 @@ import (
 )
 
 // MMDBManager 管理MaxMind数据库连接
+//
+// 数据库由配置中的数据库列表决定，按名称访问。初始化后应通过Reader和Swap访问数据库，
+// 运行时替换数据库时由mu保护。
 type MMDBManager struct {
-	ASNDB   *maxminddb.Reader
-	CityDB  *maxminddb.Reader
-	GeoCNDB *maxminddb.Reader
+	readers map[string]*maxminddb.Reader
+	usage   map[string]Usage
+	mu      sync.RWMutex
 }
 
 var (
@@ -25,12 +28,17 @@ var (
 // GetInstance 获取MMDBManager的单例实例
 func GetInstance() *MMDBManager {
 	once.Do(func() {
-		instance = &MMDBManager{}
+		instance = &MMDBManager{
+			readers: make(map[string]*maxminddb.Reader),
+			usage:   make(map[string]Usage),
+		}
 	})
 	return instance
 }
 
 // InitializeDB 初始化数据库连接
+//
+// 打开失败的数据库保持为nil，服务以可用的数据库启动，之后可以重新加载。
 func InitializeDB() error {
 	logger.Info("开始初始化数据库")
 
@@ -42,51 +50,73 @@ func InitializeDB() error {
 
 	// 获取数据库管理器实例
 	db := GetInstance()
+	db.mu.Lock()
+	defer db.mu.Unlock()
 
-	// 打开ASN数据库
-	logger.Debug("打开ASN数据库: %s", cfg.ASNDB)
-	asnDB, err := maxminddb.Open(cfg.ASNDB)
-	if err != nil {
-		return fmt.Errorf("打开ASN数据库失败: %v", err)
+	total := make(map[string]int64)
+	for _, d := range cfg.EnabledDatabases() {
+		logger.Debug("打开数据库 %s: %s (%s)", d.Name, d.Path, LoadMode(d))
+		reader, usage, err := Open(d)
+		if err != nil {
+			logger.Warn("打开数据库 %s 失败: %v", d.Name, err)
+			continue
+		}
+		db.readers[d.Name] = reader
+		db.usage[d.Name] = usage
+		total[usage.Mode] += usage.Bytes
+		logger.Info("数据库 %s 已加载，加载方式 %s，占用 %s", d.Name, usage.Mode, formatMB(usage.Bytes))
 	}
-	db.ASNDB = asnDB
 
-	// 打开City数据库
-	logger.Debug("打开City数据库: %s", cfg.CityDB)
-	cityDB, err := maxminddb.Open(cfg.CityDB)
-	if err != nil {
-		return fmt.Errorf("打开City数据库失败: %v", err)
-	}
-	db.CityDB = cityDB
+	logger.Info("数据库初始化完成，读入内存 %s，内存映射 %s，内嵌 %s",
+		formatMB(total[LoadModeMemory]), formatMB(total[LoadModeMMap]), formatMB(total[LoadModeEmbed]))
+	return nil
+}
+
+// formatMB 将字节数格式化为MB
+func formatMB(n int64) string {
+	return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+}
+
+// Reader 返回指定名称的数据库，未打开时返回nil
+func (m *MMDBManager) Reader(name string) *maxminddb.Reader {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.readers[name]
+}
 
-	// 打开GeoCN数据库
-	logger.Debug("打开GeoCN数据库: %s", cfg.GeoCNDB)
-	geoCNDB, err := maxminddb.Open(cfg.GeoCNDB)
-	if err != nil {
-		return fmt.Errorf("打开GeoCN数据库失败: %v", err)
+// Usage 返回指定名称的数据库的加载方式和占用的内存，未打开时返回false
+func (m *MMDBManager) Usage(name string) (Usage, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	usage, ok := m.usage[name]
+	return usage, ok
+}
+
+// Swap 替换指定名称的数据库并返回原数据库，由调用方在不再使用后关闭
+func (m *MMDBManager) Swap(name string, reader *maxminddb.Reader, usage Usage) (*maxminddb.Reader, error) {
+	if _, ok := config.GetInstance().Database(name); !ok {
+		return nil, fmt.Errorf("未知的数据库: %s", name)
 	}
-	db.GeoCNDB = geoCNDB
 
-	logger.Info("数据库初始化完成")
-	return nil
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	old := m.readers[name]
+	m.readers[name] = reader
+	m.usage[name] = usage
+	return old, nil
 }
 
 // Close 关闭所有数据库连接
 func (m *MMDBManager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	logger.Info("关闭数据库连接")
-	if m.ASNDB != nil {
-		if err := m.ASNDB.Close(); err != nil {
-			logger.Error("关闭ASN数据库失败: %v", err)
-		}
-	}
-	if m.CityDB != nil {
-		if err := m.CityDB.Close(); err != nil {
-			logger.Error("关闭City数据库失败: %v", err)
-		}
-	}
-	if m.GeoCNDB != nil {
-		if err := m.GeoCNDB.Close(); err != nil {
-			logger.Error("关闭GeoCN数据库失败: %v", err)
+	for name, reader := range m.readers {
+		if err := reader.Close(); err != nil {
+			logger.Error("关闭数据库 %s 失败: %v", name, err)
 		}
 	}
+	clear(m.readers)
+	clear(m.usage)
 }
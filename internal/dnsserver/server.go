@@ -0,0 +1,270 @@
+// Package dnsserver 通过DNS TXT记录提供IP查询
+//
+// 支持的查询名称(zone默认为geo.local):
+//
+//	1.2.3.4.origin.<zone>          正序的IPv4地址
+//	4.3.2.1.rorigin.<zone>         IPv4地址按字节倒序，与Team Cymru的origin.asn.cymru.com相同
+//	<32个半字节>.origin6.<zone>    IPv6地址按半字节正序
+//	<32个半字节倒序>.rorigin6.<zone> IPv6地址按半字节倒序，与ip6.arpa和origin6.asn.cymru.com相同
+//	1.2.3.4.ip.<zone>              正序的IP地址，IPv6地址中的冒号用-代替
+//	myip.<zone>                    返回发起查询的解析器地址
+//
+// 查询结果为一条TXT记录，格式为"ASN | 网段 | 国家代码 | 运营商"，如"15169 | 8.8.8.0/24 | US | Google LLC"。
+package dnsserver
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"ip-geo/internal/api/response"
+	"ip-geo/internal/config"
+	"ip-geo/internal/logger"
+	"ip-geo/internal/service"
+
+	"github.com/miekg/dns"
+)
+
+// 查询名称中区域前的标签
+const (
+	labelOrigin   = "origin"
+	labelROrigin  = "rorigin"
+	labelOrigin6  = "origin6"
+	labelROrigin6 = "rorigin6"
+	labelIP       = "ip"
+	labelMyIP     = "myip"
+)
+
+// errUnknownName 区域内不存在的名称
+var errUnknownName = errors.New("未知的查询名称")
+
+// IPService DNS查询使用的查询接口，由*service.IPService实现
+type IPService interface {
+	LookupIP(ip string) (*response.IPResponse, error)
+}
+
+// Handler 处理DNS查询
+type Handler struct {
+	ipService IPService
+	zone      string
+	ttl       uint32
+}
+
+// NewHandler 创建DNS查询处理器
+func NewHandler(cfg *config.DNSConfig, ipService IPService) *Handler {
+	return &Handler{
+		ipService: ipService,
+		zone:      dns.Fqdn(strings.ToLower(cfg.Zone)),
+		ttl:       uint32(cfg.TTL),
+	}
+}
+
+// ServeDNS 实现dns.Handler接口
+//
+// 区域外的名称返回REFUSED，区域内无法识别的名称和无效的IP返回NXDOMAIN，
+// 名称有效但查询类型不是TXT时返回没有记录的应答。
+func (h *Handler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(r)
+	defer func() {
+		if err := w.WriteMsg(m); err != nil {
+			logger.Debug("写出DNS应答失败: %v", err)
+		}
+	}()
+
+	if r.Opcode != dns.OpcodeQuery || len(r.Question) != 1 {
+		m.Rcode = dns.RcodeNotImplemented
+		return
+	}
+	q := r.Question[0]
+	name := strings.ToLower(q.Name)
+	if q.Qclass != dns.ClassINET || !dns.IsSubDomain(h.zone, name) {
+		m.Rcode = dns.RcodeRefused
+		return
+	}
+	m.Authoritative = true
+	logger.Debug("DNS查询: %s %s 来自 %s", name, dns.TypeToString[q.Qtype], w.RemoteAddr())
+
+	rel := strings.TrimSuffix(strings.TrimSuffix(name, h.zone), ".")
+	if rel == labelMyIP {
+		m.Answer = h.myIP(q, w.RemoteAddr())
+		return
+	}
+
+	ip, err := parseName(rel)
+	if err != nil {
+		m.Rcode = dns.RcodeNameError
+		return
+	}
+	if q.Qtype != dns.TypeTXT && q.Qtype != dns.TypeANY {
+		return
+	}
+	resp, err := h.ipService.LookupIP(ip.String())
+	if err != nil {
+		logger.Error("DNS查询 %s 失败: %v", name, err)
+		m.Rcode = dns.RcodeServerFailure
+		return
+	}
+	m.Answer = append(m.Answer, h.txt(q.Name, h.ttl, FormatTXT(resp)))
+}
+
+// myIP 返回发起查询的地址，TXT查询返回文本，A/AAAA查询在地址族相同时返回地址
+func (h *Handler) myIP(q dns.Question, remote net.Addr) []dns.RR {
+	var ip net.IP
+	switch addr := remote.(type) {
+	case *net.UDPAddr:
+		ip = addr.IP
+	case *net.TCPAddr:
+		ip = addr.IP
+	default:
+		return nil
+	}
+
+	hdr := dns.RR_Header{Name: q.Name, Class: dns.ClassINET}
+	switch q.Qtype {
+	case dns.TypeTXT, dns.TypeANY:
+		return []dns.RR{h.txt(q.Name, 0, ip.String())}
+	case dns.TypeA:
+		if ip4 := ip.To4(); ip4 != nil {
+			hdr.Rrtype = dns.TypeA
+			return []dns.RR{&dns.A{Hdr: hdr, A: ip4}}
+		}
+	case dns.TypeAAAA:
+		if ip.To4() == nil {
+			hdr.Rrtype = dns.TypeAAAA
+			return []dns.RR{&dns.AAAA{Hdr: hdr, AAAA: ip}}
+		}
+	}
+	return nil
+}
+
+// txt 创建TXT记录
+func (h *Handler) txt(name string, ttl uint32, text string) dns.RR {
+	return &dns.TXT{
+		Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: ttl},
+		Txt: []string{text},
+	}
+}
+
+// parseName 从区域前的部分解析IP地址
+//
+// origin和origin6下的标签按正序组成地址，rorigin和rorigin6下的标签按倒序组成地址。
+func parseName(rel string) (net.IP, error) {
+	for _, suffix := range []struct {
+		label    string
+		reversed bool
+		v4       bool
+	}{
+		{labelOrigin, false, true},
+		{labelROrigin, true, true},
+		{labelOrigin6, false, false},
+		{labelROrigin6, true, false},
+	} {
+		rest, ok := strings.CutSuffix(rel, "."+suffix.label)
+		if !ok {
+			continue
+		}
+		labels := strings.Split(rest, ".")
+		if suffix.reversed {
+			reverse(labels)
+		}
+		if suffix.v4 {
+			return parseOrigin(labels)
+		}
+		return parseOrigin6(labels)
+	}
+	if rest, ok := strings.CutSuffix(rel, "."+labelIP); ok {
+		if strings.Contains(rest, "-") {
+			return parseIP(strings.ReplaceAll(rest, "-", ":"), false)
+		}
+		return parseIP(rest, true)
+	}
+	return nil, errUnknownName
+}
+
+// parseOrigin 由正序的4个字节标签组成IPv4地址
+func parseOrigin(labels []string) (net.IP, error) {
+	if len(labels) != net.IPv4len {
+		return nil, errUnknownName
+	}
+	return parseIP(strings.Join(labels, "."), true)
+}
+
+// parseOrigin6 由正序的32个半字节标签组成IPv6地址
+func parseOrigin6(labels []string) (net.IP, error) {
+	if len(labels) != net.IPv6len*2 {
+		return nil, errUnknownName
+	}
+	var b strings.Builder
+	for i, nibble := range labels {
+		if len(nibble) != 1 {
+			return nil, errUnknownName
+		}
+		if i > 0 && i%4 == 0 {
+			b.WriteByte(':')
+		}
+		b.WriteString(nibble)
+	}
+	return parseIP(b.String(), false)
+}
+
+// parseIP 解析IP地址，v4指定地址族
+func parseIP(s string, v4 bool) (net.IP, error) {
+	ip := net.ParseIP(s)
+	if ip == nil || (ip.To4() != nil) != v4 {
+		return nil, service.ErrInvalidIP
+	}
+	return ip, nil
+}
+
+// reverse 原地反转切片
+func reverse(labels []string) {
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+}
+
+// FormatTXT 将查询结果格式化为"ASN | 网段 | 国家代码 | 运营商"，缺少的字段为NA
+//
+// 运营商优先使用ASN的组织名称，通常只包含ASCII字符，没有时使用ISP名称。
+func FormatTXT(resp *response.IPResponse) string {
+	asn := "NA"
+	if resp.ASN.Number != 0 {
+		asn = strconv.FormatUint(uint64(resp.ASN.Number), 10)
+	}
+	name := resp.ASN.Name
+	if name == "" {
+		name = resp.ISP.Name
+	}
+	return strings.Join([]string{asn, orNA(resp.Network.CIDR), orNA(resp.Location.Country.Code), orNA(name)}, " | ")
+}
+
+// orNA 空字符串返回NA
+func orNA(s string) string {
+	if s == "" {
+		return "NA"
+	}
+	return s
+}
+
+// ListenAndServe 按配置在UDP和TCP上启动DNS服务，任一协议停止时返回错误
+func ListenAndServe(cfg *config.DNSConfig, ipService *service.IPService) error {
+	if cfg.Zone == "" {
+		return fmt.Errorf("未配置DNS区域")
+	}
+	handler := NewHandler(cfg, ipService)
+
+	errChan := make(chan error, 2)
+	for _, network := range []string{"udp", "tcp"} {
+		srv := &dns.Server{Addr: cfg.Addr, Net: network, Handler: handler}
+		srv.NotifyStartedFunc = func() {
+			logger.Info("DNS服务启动在 %s %s，区域: %s", network, cfg.Addr, handler.zone)
+		}
+		go func() {
+			errChan <- fmt.Errorf("DNS服务(%s)停止: %v", network, srv.ListenAndServe())
+		}()
+	}
+	return <-errChan
+}
@@ -0,0 +1,166 @@
+package dnsserver
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"ip-geo/internal/api/response"
+	"ip-geo/internal/config"
+	"ip-geo/internal/service"
+
+	"github.com/miekg/dns"
+)
+
+// stubIPService 不依赖数据库文件的查询服务，网段为查询的IP本身
+type stubIPService struct{}
+
+func (stubIPService) LookupIP(ip string) (*response.IPResponse, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, service.ErrInvalidIP
+	}
+	bits := 128
+	if parsed.To4() != nil {
+		bits = 32
+	}
+	resp := &response.IPResponse{IP: ip}
+	resp.ASN.Number = 64496
+	resp.ASN.Name = "TEST-AS"
+	resp.Network.CIDR = (&net.IPNet{IP: parsed, Mask: net.CIDRMask(bits, bits)}).String()
+	resp.Location.Country.Code = "US"
+	return resp, nil
+}
+
+// startServers 在127.0.0.1的随机端口上启动UDP和TCP服务，返回各协议的地址
+func startServers(t *testing.T) map[string]string {
+	t.Helper()
+	handler := NewHandler(&config.DNSConfig{Zone: "geo.local", TTL: 300}, stubIPService{})
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	servers := []*dns.Server{
+		{PacketConn: pc, Net: "udp", Handler: handler},
+		{Listener: ln, Net: "tcp", Handler: handler},
+	}
+	for _, srv := range servers {
+		started := make(chan struct{})
+		srv.NotifyStartedFunc = func() { close(started) }
+		go srv.ActivateAndServe()
+		<-started
+		t.Cleanup(func() { srv.Shutdown() })
+	}
+	return map[string]string{"udp": pc.LocalAddr().String(), "tcp": ln.Addr().String()}
+}
+
+// exchange 发送一个查询
+func exchange(t *testing.T, network, addr, name string, qtype uint16) *dns.Msg {
+	t.Helper()
+	client := &dns.Client{Net: network, Timeout: 5 * time.Second}
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(name), qtype)
+	r, _, err := client.Exchange(m, addr)
+	if err != nil {
+		t.Fatalf("%s %s: %v", network, name, err)
+	}
+	return r
+}
+
+// rorigin6Name 返回IPv6地址在rorigin6下的名称
+func rorigin6Name(ip string) string {
+	arpa, _ := dns.ReverseAddr(ip)
+	return strings.TrimSuffix(arpa, "ip6.arpa.") + "rorigin6.geo.local"
+}
+
+// origin6Name 返回IPv6地址在origin6下的名称
+func origin6Name(ip string) string {
+	var labels []string
+	for _, b := range net.ParseIP(ip).To16() {
+		labels = append(labels, fmt.Sprintf("%x", b>>4), fmt.Sprintf("%x", b&0xf))
+	}
+	return strings.Join(labels, ".") + ".origin6.geo.local"
+}
+
+func TestServeDNS(t *testing.T) {
+	addrs := startServers(t)
+
+	tests := []struct {
+		name  string
+		qtype uint16
+		rcode int
+		// txt 为空时应答中没有记录
+		txt string
+	}{
+		// origin下为正序，rorigin下为倒序，地址不对称时结果不同
+		{name: "1.2.3.4.origin.geo.local", qtype: dns.TypeTXT, txt: "64496 | 1.2.3.4/32 | US | TEST-AS"},
+		{name: "8.8.4.4.ORIGIN.GEO.LOCAL", qtype: dns.TypeTXT, txt: "64496 | 8.8.4.4/32 | US | TEST-AS"},
+		{name: "4.3.2.1.rorigin.geo.local", qtype: dns.TypeTXT, txt: "64496 | 1.2.3.4/32 | US | TEST-AS"},
+		{name: "1.2.3.4.rorigin.geo.local", qtype: dns.TypeTXT, txt: "64496 | 4.3.2.1/32 | US | TEST-AS"},
+		{name: origin6Name("2001:db8::1"), qtype: dns.TypeTXT, txt: "64496 | 2001:db8::1/128 | US | TEST-AS"},
+		{name: rorigin6Name("2001:db8::1"), qtype: dns.TypeTXT, txt: "64496 | 2001:db8::1/128 | US | TEST-AS"},
+		{name: "1.2.3.4.ip.geo.local", qtype: dns.TypeTXT, txt: "64496 | 1.2.3.4/32 | US | TEST-AS"},
+		{name: "2001-db8--1.ip.geo.local", qtype: dns.TypeTXT, txt: "64496 | 2001:db8::1/128 | US | TEST-AS"},
+		// 名称有效但查询类型不是TXT
+		{name: "1.2.3.4.origin.geo.local", qtype: dns.TypeA},
+
+		{name: "myip.geo.local", qtype: dns.TypeTXT, txt: "127.0.0.1"},
+		{name: "myip.geo.local", qtype: dns.TypeAAAA},
+
+		{name: "1.2.3.origin.geo.local", qtype: dns.TypeTXT, rcode: dns.RcodeNameError},
+		{name: "300.2.3.4.origin.geo.local", qtype: dns.TypeTXT, rcode: dns.RcodeNameError},
+		{name: "4.3.2.300.rorigin.geo.local", qtype: dns.TypeTXT, rcode: dns.RcodeNameError},
+		{name: "4.3.2.1.origin6.geo.local", qtype: dns.TypeTXT, rcode: dns.RcodeNameError},
+		{name: "4.3.2.1.rorigin6.geo.local", qtype: dns.TypeTXT, rcode: dns.RcodeNameError},
+		{name: "2001-db8--1.origin.geo.local", qtype: dns.TypeTXT, rcode: dns.RcodeNameError},
+		{name: "1.2.3.4.geo.local", qtype: dns.TypeTXT, rcode: dns.RcodeNameError},
+		{name: "foo.geo.local", qtype: dns.TypeTXT, rcode: dns.RcodeNameError},
+		{name: "1.2.3.4.origin.example.com", qtype: dns.TypeTXT, rcode: dns.RcodeRefused},
+	}
+	for network, addr := range addrs {
+		for _, tt := range tests {
+			t.Run(network+"/"+dns.TypeToString[tt.qtype]+"/"+tt.name, func(t *testing.T) {
+				r := exchange(t, network, addr, tt.name, tt.qtype)
+				if r.Rcode != tt.rcode {
+					t.Fatalf("rcode = %s, want %s", dns.RcodeToString[r.Rcode], dns.RcodeToString[tt.rcode])
+				}
+				if tt.txt == "" {
+					if len(r.Answer) != 0 {
+						t.Errorf("answer = %v, want none", r.Answer)
+					}
+					return
+				}
+				if len(r.Answer) != 1 {
+					t.Fatalf("answer = %v, want one TXT record", r.Answer)
+				}
+				txt, ok := r.Answer[0].(*dns.TXT)
+				if !ok {
+					t.Fatalf("answer = %v, want TXT", r.Answer[0])
+				}
+				if got := strings.Join(txt.Txt, ""); got != tt.txt {
+					t.Errorf("TXT = %q, want %q", got, tt.txt)
+				}
+			})
+		}
+	}
+}
+
+func TestServeDNSMyIPAddress(t *testing.T) {
+	for network, addr := range startServers(t) {
+		r := exchange(t, network, addr, "myip.geo.local", dns.TypeA)
+		if len(r.Answer) != 1 {
+			t.Fatalf("%s: answer = %v, want one A record", network, r.Answer)
+		}
+		a, ok := r.Answer[0].(*dns.A)
+		if !ok || !a.A.Equal(net.IPv4(127, 0, 0, 1)) {
+			t.Errorf("%s: answer = %v, want 127.0.0.1", network, r.Answer[0])
+		}
+	}
+}
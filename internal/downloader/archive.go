@@ -0,0 +1,174 @@
+package downloader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/ulikunitz/xz"
+)
+
+// 下载文件的格式
+const (
+	formatMMDB  = "mmdb"
+	formatGz    = "gz"
+	formatXz    = "xz"
+	formatZip   = "zip"
+	formatTarGz = "tar.gz"
+	formatTarXz = "tar.xz"
+)
+
+// DefaultMember 默认从压缩包中提取的文件
+const DefaultMember = "*.mmdb"
+
+// detectFormat 根据下载地址的后缀判断文件格式
+func detectFormat(rawURL string) string {
+	p := strings.ToLower(strings.SplitN(rawURL, "?", 2)[0])
+	switch {
+	case strings.HasSuffix(p, ".tar.gz"), strings.HasSuffix(p, ".tgz"):
+		return formatTarGz
+	case strings.HasSuffix(p, ".tar.xz"), strings.HasSuffix(p, ".txz"):
+		return formatTarXz
+	case strings.HasSuffix(p, ".gz"):
+		return formatGz
+	case strings.HasSuffix(p, ".xz"):
+		return formatXz
+	case strings.HasSuffix(p, ".zip"):
+		return formatZip
+	}
+	return formatMMDB
+}
+
+// validFormat 判断是否为支持的格式
+func validFormat(format string) bool {
+	switch format {
+	case formatMMDB, formatGz, formatXz, formatZip, formatTarGz, formatTarXz:
+		return true
+	}
+	return false
+}
+
+// matchMember 判断压缩包中的文件名是否匹配，模式不含/时只匹配文件名
+func matchMember(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		name = path.Base(name)
+	}
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
+
+// extract 将下载的文件解压后写入dst
+//
+// 除zip外均为流式解压，不会将整个压缩包读入内存。
+func extract(archive, format, member, dst string) error {
+	if format == formatZip {
+		return extractZip(archive, member, dst)
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch format {
+	case formatGz:
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("解压失败: %v", err)
+		}
+		defer gz.Close()
+		return writeFile(dst, gz)
+	case formatXz:
+		xr, err := xz.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("解压失败: %v", err)
+		}
+		return writeFile(dst, xr)
+	case formatTarGz:
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("解压失败: %v", err)
+		}
+		defer gz.Close()
+		return extractTar(gz, member, dst)
+	case formatTarXz:
+		xr, err := xz.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("解压失败: %v", err)
+		}
+		return extractTar(xr, member, dst)
+	}
+	return fmt.Errorf("不支持的格式: %s", format)
+}
+
+// extractTar 从tar流中提取第一个匹配的文件
+//
+// MaxMind的压缩包中数据库位于以版本和日期命名的目录下，如GeoLite2-ASN_20250101/GeoLite2-ASN.mmdb。
+func extractTar(r io.Reader, member, dst string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return fmt.Errorf("压缩包中没有匹配 %s 的文件", member)
+		}
+		if err != nil {
+			return fmt.Errorf("读取压缩包失败: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg || !matchMember(member, header.Name) {
+			continue
+		}
+		if err := writeFile(dst, tr); err != nil {
+			return fmt.Errorf("提取 %s 失败: %v", header.Name, err)
+		}
+		return nil
+	}
+}
+
+// extractZip 从zip文件中提取第一个匹配的文件
+func extractZip(archive, member, dst string) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("读取压缩包失败: %v", err)
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !matchMember(member, f.Name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("提取 %s 失败: %v", f.Name, err)
+		}
+		defer rc.Close()
+		if err := writeFile(dst, rc); err != nil {
+			return fmt.Errorf("提取 %s 失败: %v", f.Name, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("压缩包中没有匹配 %s 的文件", member)
+}
+
+// writeFile 将r的内容写入文件，失败时删除文件
+func writeFile(dst string, r io.Reader) error {
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return nil
+}
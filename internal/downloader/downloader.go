@@ -1,36 +1,98 @@
 package downloader
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
+	"ip-geo/internal/config"
 	"ip-geo/internal/logger"
+	"ip-geo/internal/snapshot"
 )
 
+// mmdbFiles 内置的下载地址，键为默认的数据库文件路径
 var mmdbFiles = map[string]string{
-	"mmdb/GeoIP2-City.mmdb":   "https://pan.dnslin.com/d/pan/GeoIP2-City.mmdb",
-	"mmdb/GeoLite2-ASN.mmdb":  "https://github.com/P3TERX/GeoLite.mmdb/raw/download/GeoLite2-ASN.mmdb",
-	"mmdb/GeoCN.mmdb":         "http://github.com/ljxi/GeoCN/releases/download/Latest/GeoCN.mmdb",
+	"mmdb/GeoIP2-City.mmdb":  "https://pan.dnslin.com/d/pan/GeoIP2-City.mmdb",
+	"mmdb/GeoLite2-ASN.mmdb": "https://github.com/P3TERX/GeoLite.mmdb/raw/download/GeoLite2-ASN.mmdb",
+	"mmdb/GeoCN.mmdb":        "http://github.com/ljxi/GeoCN/releases/download/Latest/GeoCN.mmdb",
 }
 
-// EnsureMMDBFiles 确保所有必需的MMDB文件存在，如果不存在则下载
+// 下载的默认参数
+const (
+	defaultMaxRetries     = 5
+	defaultIdleTimeout    = time.Minute
+	responseHeaderTimeout = 30 * time.Second
+)
+
+// Source 返回数据库文件的下载地址
+func Source(path string) (string, bool) {
+	src, ok, err := lookupSource(path)
+	if err != nil || !ok {
+		return "", false
+	}
+	return src.mirrors[0].url, true
+}
+
+// Versions 返回数据库文件的版本存储
+func Versions(path string) *snapshot.Store {
+	return snapshot.New(path, config.GetInstance().SnapshotKeep)
+}
+
+// Download 重新下载数据库文件，作为新版本加入版本存储
+func Download(path string) error {
+	src, ok, err := lookupSource(path)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("没有配置 %s 的下载地址", path)
+	}
+
+	logger.Info("开始下载数据库: %s", path)
+	if err := downloadFileWithRetry(src, path); err != nil {
+		return fmt.Errorf("下载文件 %s 失败: %v", path, err)
+	}
+	logger.Info("数据库下载完成: %s", path)
+	return nil
+}
+
+// EnsureMMDBFiles 确保已启用的数据库文件存在，如果不存在则下载
 func EnsureMMDBFiles() error {
-	// 创建mmdb目录
-	if err := os.MkdirAll("mmdb", 0755); err != nil {
-		return fmt.Errorf("创建mmdb目录失败: %v", err)
+	all, configErrs := sources()
+
+	// 下载来源配置无效的数据库只在文件不存在时报告错误
+	var errs []error
+	for filePath, err := range configErrs {
+		if fileExists(filePath) {
+			logger.Warn("数据库 %s 的下载来源无效，无法更新: %v", filePath, err)
+			continue
+		}
+		errs = append(errs, fmt.Errorf("下载文件 %s 失败: %v", filePath, err))
+	}
+
+	// 创建数据库所在目录
+	for filePath := range all {
+		if dir := filepath.Dir(filePath); dir != "." {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("创建目录 %s 失败: %v", dir, err)
+			}
+		}
 	}
 
 	var wg sync.WaitGroup
-	errChan := make(chan error, len(mmdbFiles))
+	errChan := make(chan error, len(all))
 
-	for filePath, url := range mmdbFiles {
+	for filePath, src := range all {
 		if !fileExists(filePath) {
 			wg.Add(1)
-			go func(fp, u string) {
+			go func(fp string, u *source) {
 				defer wg.Done()
 				logger.Info("开始下载数据库: %s", fp)
 				if err := downloadFileWithRetry(u, fp); err != nil {
@@ -38,7 +100,7 @@ func EnsureMMDBFiles() error {
 					return
 				}
 				logger.Info("数据库下载完成: %s", fp)
-			}(filePath, url)
+			}(filePath, src)
 		} else {
 			logger.Debug("数据库文件已存在: %s", filePath)
 		}
@@ -50,14 +112,12 @@ func EnsureMMDBFiles() error {
 		close(errChan)
 	}()
 
-	// 收集错误
+	// 收集错误，一个数据库下载失败不影响其他数据库
 	for err := range errChan {
-		if err != nil {
-			return err
-		}
+		errs = append(errs, err)
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 // fileExists 检查文件是否存在
@@ -70,76 +130,102 @@ func fileExists(filename string) bool {
 }
 
 // downloadFileWithRetry 带重试的文件下载
-func downloadFileWithRetry(url, filepath string) error {
-	maxRetries := 3
-	var lastErr error
+//
+// 每次尝试按镜像的选择方式依次尝试各个镜像，全部失败后按指数退避等待再重试。
+// 已下载的部分在重试时从断点继续，有未完成下载的镜像优先尝试。
+// 认证失败、文件不存在或文件被拒绝的镜像不再重试。
+func downloadFileWithRetry(src *source, filepath string) error {
+	maxRetries := config.GetInstance().Download.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultMaxRetries
+	}
+	client, err := newHTTPClient()
+	if err != nil {
+		return err
+	}
 
-	for i := 0; i < maxRetries; i++ {
+	var lastErr error
+	mirrors := src.mirrors
+	for i := 0; i < maxRetries && len(mirrors) > 0; i++ {
 		if i > 0 {
-			logger.Info("重试下载 %s (第 %d 次)", filepath, i+1)
-			time.Sleep(time.Second * time.Duration(i+1)) // 递增延迟
+			delay := retryDelay(i-1, lastErr)
+			logger.Info("%v 后重试下载 %s (第 %d 次)", delay.Round(time.Millisecond), filepath, i+1)
+			time.Sleep(delay)
 		}
 
-		if err := downloadFileWithProgress(url, filepath); err != nil {
+		var available []*mirror
+		for _, m := range orderMirrors(client, mirrors, src.strategy, filepath) {
+			err := downloadFileWithProgress(client, m, filepath)
+			if err == nil {
+				return nil
+			}
 			lastErr = err
-			logger.Warn("下载失败 %s: %v", filepath, err)
-			continue
+			logger.Warn("从 %s 下载 %s 失败: %v", m.url, filepath, err)
+
+			var perm *permanentError
+			if !errors.As(err, &perm) {
+				available = append(available, m)
+			}
 		}
-		return nil
+		mirrors = available
 	}
 
+	if len(mirrors) == 0 {
+		return lastErr
+	}
 	return fmt.Errorf("达到最大重试次数: %v", lastErr)
 }
 
 // downloadFileWithProgress 带进度的文件下载
-func downloadFileWithProgress(url, filepath string) error {
-	// 创建临时文件
+//
+// 下载内容先写入.tmp文件，完成并通过校验后再解压。进度和校验和按下载的原始字节计算。
+func downloadFileWithProgress(client *http.Client, m *mirror, filepath string) error {
 	tmpFile := filepath + ".tmp"
-	out, err := os.Create(tmpFile)
+	part := partialFile(m, filepath)
+
+	// 先获取校验和，下载完成后核对
+	checksum, err := fetchChecksum(client, m)
 	if err != nil {
 		return err
 	}
-	defer out.Close()
+	if checksum == "" {
+		if config.GetInstance().Download.RequireChecksum {
+			return permanent(fmt.Errorf("没有找到校验文件 %s", m.checksumURL))
+		}
+		logger.Warn("%s 没有校验文件，跳过校验和检查", m.url)
+	}
 
-	// 发送GET请求
-	client := &http.Client{
-		Timeout: 30 * time.Second,
+	// 下载失败时保留已下载的部分，下次从断点继续
+	if err := fetchPartial(client, m, part, filepath); err != nil {
+		return err
 	}
-	resp, err := client.Get(url)
+
+	sum, err := fileSHA256(part)
 	if err != nil {
-		os.Remove(tmpFile)
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		os.Remove(tmpFile)
-		return fmt.Errorf("下载失败，状态码: %d", resp.StatusCode)
+	if checksum != "" && sum != checksum {
+		removePartial(part)
+		return fmt.Errorf("SHA-256校验失败，期望 %s，实际 %s", checksum, sum)
 	}
 
-	// 获取文件大小
-	fileSize := resp.ContentLength
-
-	// 创建进度读取器
-	reader := &ProgressReader{
-		Reader:     resp.Body,
-		Total:      fileSize,
-		FilePath:   filepath,
-		LastUpdate: time.Now(),
+	if part != tmpFile {
+		err := extract(part, m.format, m.member, tmpFile)
+		removePartial(part)
+		if err != nil {
+			return err
+		}
+	} else {
+		os.Remove(part + ".meta")
 	}
 
-	// 复制响应内容到文件
-	_, err = io.Copy(out, reader)
-	if err != nil {
+	if err := verifyDatabase(tmpFile, filepath); err != nil {
 		os.Remove(tmpFile)
 		return err
 	}
 
-	// 关闭临时文件
-	out.Close()
-
-	// 重命名临时文件为目标文件
-	if err := os.Rename(tmpFile, filepath); err != nil {
+	// 加入版本存储并切换为当前版本，数据库路径为指向该版本的符号链接
+	if _, err := Versions(filepath).Add(tmpFile, m.url); err != nil {
 		os.Remove(tmpFile)
 		return err
 	}
@@ -147,6 +233,29 @@ func downloadFileWithProgress(url, filepath string) error {
 	return nil
 }
 
+// idleTimeout 返回下载的空闲超时时间
+func idleTimeout() time.Duration {
+	if seconds := config.GetInstance().Download.IdleTimeout; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultIdleTimeout
+}
+
+// fileSHA256 计算文件的SHA-256
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // ProgressReader 进度读取器
 type ProgressReader struct {
 	Reader     io.Reader
@@ -165,7 +274,7 @@ func (pr *ProgressReader) Read(p []byte) (int, error) {
 	if time.Since(pr.LastUpdate) > time.Second {
 		if pr.Total > 0 {
 			progress := float64(pr.Current) / float64(pr.Total) * 100
-			logger.Info("下载进度 %s: %.2f%% (%d/%d bytes)", 
+			logger.Info("下载进度 %s: %.2f%% (%d/%d bytes)",
 				pr.FilePath, progress, pr.Current, pr.Total)
 		} else {
 			logger.Info("下载进度 %s: %d bytes", pr.FilePath, pr.Current)
@@ -174,4 +283,4 @@ func (pr *ProgressReader) Read(p []byte) (int, error) {
 	}
 
 	return n, err
-} 
\ No newline at end of file
+}
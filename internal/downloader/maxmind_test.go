@@ -0,0 +1,238 @@
+package downloader
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"ip-geo/internal/config"
+	"ip-geo/internal/mmdbwriter"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+const (
+	testAccountID  = 123456
+	testLicenseKey = "test-license-key"
+	testEditionID  = "GeoLite2-City"
+)
+
+// testCityRecord 测试数据库中的记录
+type testCityRecord struct {
+	City string `maxminddb:"city"`
+}
+
+// testArchive 生成包含.mmdb文件的tar.gz压缩包，与MaxMind接口返回的格式一致
+func testArchive(t *testing.T) []byte {
+	t.Helper()
+	tree := mmdbwriter.New(mmdbwriter.Metadata{DatabaseType: testEditionID, Languages: []string{"en"}})
+	_, network, _ := net.ParseCIDR("1.2.3.0/24")
+	if err := tree.Insert(network, map[string]any{"city": "Test City"}); err != nil {
+		t.Fatal(err)
+	}
+	var db bytes.Buffer
+	if _, err := tree.WriteTo(&db); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{testEditionID + "_20261018/LICENSE.txt", []byte("license")},
+		{testEditionID + "_20261018/" + testEditionID + ".mmdb", db.Bytes()},
+	}
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// maxMindServer 模拟MaxMind下载接口，记录收到的请求
+type maxMindServer struct {
+	*httptest.Server
+	archive  []byte
+	checksum string
+
+	mu       sync.Mutex
+	requests []string
+}
+
+func newMaxMindServer(t *testing.T) *maxMindServer {
+	t.Helper()
+	s := &maxMindServer{archive: testArchive(t)}
+	sum := sha256.Sum256(s.archive)
+	s.checksum = hex.EncodeToString(sum[:])
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.URL.RequestURI())
+		checksum := s.checksum
+		s.mu.Unlock()
+
+		username, password, ok := r.BasicAuth()
+		if !ok || username != fmt.Sprint(testAccountID) || password != testLicenseKey {
+			http.Error(w, "invalid credentials", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/geoip/databases/"+testEditionID+"/download" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.URL.Query().Get("suffix") {
+		case "tar.gz":
+			w.Write(s.archive)
+		case "tar.gz.sha256":
+			fmt.Fprintf(w, "%s  %s_20261018.tar.gz\n", checksum, testEditionID)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// setChecksum 修改接口返回的校验和
+func (s *maxMindServer) setChecksum(sum string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checksum = sum
+}
+
+// useConfig 修改全局配置，测试结束后恢复
+func useConfig(t *testing.T, databases []config.Database, baseURL string) {
+	t.Helper()
+	cfg := config.GetInstance()
+	oldDownload, oldDatabases := cfg.Download, cfg.Databases
+	t.Cleanup(func() {
+		cfg.Download, cfg.Databases = oldDownload, oldDatabases
+	})
+
+	cfg.Databases = databases
+	cfg.Download.MaxRetries = 1
+	cfg.Download.RequireChecksum = true
+	cfg.Download.Proxy = ""
+	cfg.Download.MaxMind.AccountID = testAccountID
+	cfg.Download.MaxMind.LicenseKey = testLicenseKey
+	cfg.Download.MaxMind.BaseURL = baseURL
+}
+
+func TestMaxMindDownload(t *testing.T) {
+	srv := newMaxMindServer(t)
+	path := filepath.Join(t.TempDir(), "GeoLite2-City.mmdb")
+	useConfig(t, []config.Database{
+		{Name: "city", Path: path, Download: &config.DownloadSource{EditionID: testEditionID}},
+	}, srv.URL)
+
+	if err := Download(path); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+
+	want := []string{
+		"/geoip/databases/" + testEditionID + "/download?suffix=tar.gz.sha256",
+		"/geoip/databases/" + testEditionID + "/download?suffix=tar.gz",
+	}
+	if got := strings.Join(srv.requests, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("requests =\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+
+	// 从压缩包中提取的.mmdb文件作为当前版本
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer reader.Close()
+	if reader.Metadata.DatabaseType != testEditionID {
+		t.Errorf("DatabaseType = %q, want %q", reader.Metadata.DatabaseType, testEditionID)
+	}
+	var record testCityRecord
+	if err := reader.Lookup(net.ParseIP("1.2.3.4"), &record); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if record.City != "Test City" {
+		t.Errorf("City = %q, want %q", record.City, "Test City")
+	}
+}
+
+func TestMaxMindDownloadChecksumMismatch(t *testing.T) {
+	srv := newMaxMindServer(t)
+	srv.setChecksum(strings.Repeat("0", 64))
+	path := filepath.Join(t.TempDir(), "GeoLite2-City.mmdb")
+	useConfig(t, []config.Database{
+		{Name: "city", Path: path, Download: &config.DownloadSource{EditionID: testEditionID}},
+	}, srv.URL)
+
+	err := Download(path)
+	if err == nil || !strings.Contains(err.Error(), "SHA-256校验失败") {
+		t.Fatalf("Download error = %v, want checksum mismatch", err)
+	}
+	if fileExists(path) {
+		t.Errorf("%s exists after checksum mismatch", path)
+	}
+}
+
+func TestMaxMindDownloadUnauthorized(t *testing.T) {
+	srv := newMaxMindServer(t)
+	path := filepath.Join(t.TempDir(), "GeoLite2-City.mmdb")
+	useConfig(t, []config.Database{
+		{Name: "city", Path: path, Download: &config.DownloadSource{EditionID: testEditionID}},
+	}, srv.URL)
+	config.GetInstance().Download.MaxMind.LicenseKey = "wrong"
+
+	if err := Download(path); err == nil {
+		t.Fatal("Download succeeded with wrong license key")
+	}
+}
+
+func TestSourcesSkipsInvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "GeoLite2-City.mmdb")
+	invalid := filepath.Join(dir, "custom.mmdb")
+	useConfig(t, []config.Database{
+		{Name: "city", Path: valid, Download: &config.DownloadSource{EditionID: testEditionID}},
+		{Name: "custom", Path: invalid, Download: &config.DownloadSource{URL: "http://example.com/custom.mmdb", Strategy: "unknown"}},
+	}, "http://127.0.0.1")
+
+	all, errs := sources()
+	if _, ok := all[valid]; !ok {
+		t.Errorf("sources() missing %s", valid)
+	}
+	if _, ok := all[invalid]; ok {
+		t.Errorf("sources() contains invalid %s", invalid)
+	}
+	if errs[invalid] == nil {
+		t.Errorf("sources() has no error for %s", invalid)
+	}
+
+	// 其他数据库的配置错误不影响查找
+	if _, ok, err := lookupSource(valid); !ok || err != nil {
+		t.Errorf("lookupSource(%s) = %v, %v", valid, ok, err)
+	}
+	if _, _, err := lookupSource(invalid); err == nil {
+		t.Errorf("lookupSource(%s) returned no error", invalid)
+	}
+}
@@ -0,0 +1,133 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"ip-geo/internal/config"
+	"ip-geo/internal/logger"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// newHTTPClient 创建下载使用的HTTP客户端
+//
+// 不设置整个请求的超时时间，大文件下载由空闲超时控制。代理使用环境变量HTTP_PROXY/HTTPS_PROXY/NO_PROXY，
+// 配置的proxy和no_proxy分别覆盖对应的环境变量，只配置no_proxy时也对环境变量中的代理生效。
+func newHTTPClient() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = responseHeaderTimeout
+
+	cfg := config.GetInstance().Download
+	proxyConfig := httpproxy.FromEnvironment()
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("无效的代理地址: %v", err)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("不支持的代理协议: %s", proxyURL.Scheme)
+		}
+		proxyConfig.HTTPProxy = cfg.Proxy
+		proxyConfig.HTTPSProxy = cfg.Proxy
+	}
+	if cfg.NoProxy != "" {
+		proxyConfig.NoProxy = cfg.NoProxy
+	}
+
+	proxyFunc := proxyConfig.ProxyFunc()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// partialFile 返回镜像未完成下载的文件路径
+func partialFile(m *mirror, filepath string) string {
+	if m.format == formatMMDB {
+		return filepath + ".tmp"
+	}
+	return filepath + "." + m.format + ".tmp"
+}
+
+// orderMirrors 返回本次尝试镜像的顺序
+//
+// race方式同时请求所有镜像，按响应先后排列，请求失败的镜像排在最后。
+// 有未完成下载的镜像排在最前面，以便从断点继续。
+func orderMirrors(client *http.Client, mirrors []*mirror, strategy, filepath string) []*mirror {
+	ordered := mirrors
+	if strategy == StrategyRace && len(mirrors) > 1 {
+		ordered = raceMirrors(client, mirrors)
+	}
+
+	for i, m := range ordered {
+		if meta := readPartialMeta(partialFile(m, filepath)); meta != nil && meta.URL == m.url {
+			if i > 0 {
+				ordered = append([]*mirror{m}, slices.Delete(slices.Clone(ordered), i, i+1)...)
+			}
+			break
+		}
+	}
+	return ordered
+}
+
+// raceMirrors 同时请求所有镜像的第一个字节，按响应先后排列
+func raceMirrors(client *http.Client, mirrors []*mirror) []*mirror {
+	ctx, cancel := context.WithTimeout(context.Background(), responseHeaderTimeout)
+	defer cancel()
+
+	type result struct {
+		index int
+		err   error
+	}
+	results := make(chan result, len(mirrors))
+	for i, m := range mirrors {
+		go func() {
+			results <- result{index: i, err: probeMirror(ctx, client, m)}
+		}()
+	}
+
+	ordered := make([]*mirror, 0, len(mirrors))
+	failed := make([]bool, len(mirrors))
+	for range mirrors {
+		r := <-results
+		if r.err != nil {
+			logger.Warn("镜像 %s 不可用: %v", mirrors[r.index].url, r.err)
+			failed[r.index] = true
+			continue
+		}
+		ordered = append(ordered, mirrors[r.index])
+	}
+	for i, m := range mirrors {
+		if failed[i] {
+			ordered = append(ordered, m)
+		}
+	}
+	return ordered
+}
+
+// probeMirror 请求镜像的第一个字节，检查镜像是否可用
+func probeMirror(ctx context.Context, client *http.Client, m *mirror) error {
+	req, err := m.newRequest(m.url)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Range", "bytes=0-0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return statusError("请求失败", resp)
+	}
+	return nil
+}
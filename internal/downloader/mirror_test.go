@@ -0,0 +1,58 @@
+package downloader
+
+import (
+	"net/http"
+	"testing"
+
+	"ip-geo/internal/config"
+)
+
+func TestNewHTTPClientNoProxy(t *testing.T) {
+	cfg := config.GetInstance()
+	oldDownload := cfg.Download
+	t.Cleanup(func() { cfg.Download = oldDownload })
+
+	tests := []struct {
+		name    string
+		proxy   string
+		env     string
+		noProxy string
+	}{
+		{name: "configured proxy", proxy: "http://proxy.example.com:3128", noProxy: "mirror.example.cn"},
+		// 只配置no_proxy时对环境变量中的代理生效
+		{name: "environment proxy", env: "http://proxy.example.com:3128", noProxy: "mirror.example.cn"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HTTP_PROXY", tt.env)
+			t.Setenv("HTTPS_PROXY", tt.env)
+			t.Setenv("NO_PROXY", "")
+			cfg.Download.Proxy = tt.proxy
+			cfg.Download.NoProxy = tt.noProxy
+
+			client, err := newHTTPClient()
+			if err != nil {
+				t.Fatal(err)
+			}
+			proxy := client.Transport.(*http.Transport).Proxy
+
+			for url, want := range map[string]string{
+				"https://mirror.example.cn/GeoLite2-City.mmdb":    "",
+				"https://download.example.com/GeoLite2-City.mmdb": "http://proxy.example.com:3128",
+			} {
+				req, _ := http.NewRequest(http.MethodGet, url, nil)
+				got, err := proxy(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				gotURL := ""
+				if got != nil {
+					gotURL = got.String()
+				}
+				if gotURL != want {
+					t.Errorf("proxy(%s) = %q, want %q", url, gotURL, want)
+				}
+			}
+		})
+	}
+}
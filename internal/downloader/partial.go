@@ -0,0 +1,211 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"ip-geo/internal/logger"
+)
+
+// partialMeta 未完成下载的信息，用于判断续传时服务器上的文件是否发生变化
+type partialMeta struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// validator 返回If-Range使用的校验值，优先使用强ETag
+func (m *partialMeta) validator() string {
+	if m.ETag != "" && !strings.HasPrefix(m.ETag, "W/") {
+		return m.ETag
+	}
+	return m.LastModified
+}
+
+// readPartialMeta 读取未完成下载的信息
+func readPartialMeta(part string) *partialMeta {
+	data, err := os.ReadFile(part + ".meta")
+	if err != nil {
+		return nil
+	}
+	m := &partialMeta{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil
+	}
+	return m
+}
+
+// writePartialMeta 保存未完成下载的信息
+func writePartialMeta(part string, m *partialMeta) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(part+".meta", data, 0644)
+}
+
+// removePartial 删除未完成的下载
+func removePartial(part string) {
+	os.Remove(part)
+	os.Remove(part + ".meta")
+}
+
+// fetchPartial 将下载内容写入part文件，part文件已有内容且服务器上的文件没有变化时从断点继续下载
+//
+// 服务器不支持Range请求或文件已变化时返回完整内容，此时从头下载。
+func fetchPartial(client *http.Client, m *mirror, part, filepath string) error {
+	var offset int64
+	meta := readPartialMeta(part)
+	if info, err := os.Stat(part); err == nil && meta != nil && meta.URL == m.url && meta.validator() != "" {
+		offset = info.Size()
+	} else {
+		removePartial(part)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err := m.newRequest(m.url)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", meta.validator())
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	total := resp.ContentLength
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if offset > 0 {
+			logger.Info("%s 已发生变化或服务器不支持续传，从头下载", m.url)
+		}
+		offset = 0
+		meta = &partialMeta{
+			URL:          m.url,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		}
+		if err := writePartialMeta(part, meta); err != nil {
+			return err
+		}
+	case http.StatusPartialContent:
+		start, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			removePartial(part)
+			return fmt.Errorf("无效的Content-Range: %s", resp.Header.Get("Content-Range"))
+		}
+		logger.Info("从 %d 字节处继续下载 %s", offset, filepath)
+		total = size
+		flag = os.O_WRONLY | os.O_APPEND
+	case http.StatusRequestedRangeNotSatisfiable:
+		removePartial(part)
+		return fmt.Errorf("服务器不接受续传范围，将从头下载")
+	default:
+		return statusError("下载失败", resp)
+	}
+
+	out, err := os.OpenFile(part, flag, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	// 创建进度读取器，续传时进度包含已下载的部分
+	reader := &ProgressReader{
+		Reader:     newIdleTimeoutReader(resp.Body, idleTimeout(), cancel),
+		Total:      total,
+		Current:    offset,
+		FilePath:   filepath,
+		LastUpdate: time.Now(),
+	}
+	if _, err := io.Copy(out, reader); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	if total >= 0 && reader.Current != total {
+		return fmt.Errorf("文件不完整，收到 %d 字节，文件大小为 %d", reader.Current, total)
+	}
+	return nil
+}
+
+// parseContentRange 解析"bytes start-end/size"形式的Content-Range，大小未知时size为-1
+func parseContentRange(value string) (start, size int64, ok bool) {
+	rest, found := strings.CutPrefix(value, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	span, sizeStr, found := strings.Cut(rest, "/")
+	if !found {
+		return 0, 0, false
+	}
+	startStr, _, found := strings.Cut(span, "-")
+	if !found {
+		return 0, 0, false
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if sizeStr == "*" {
+		return start, -1, true
+	}
+	size, err = strconv.ParseInt(sizeStr, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, size, true
+}
+
+// idleTimeoutReader 超过timeout没有收到数据时取消请求
+type idleTimeoutReader struct {
+	r       io.Reader
+	timeout time.Duration
+	timer   *time.Timer
+	expired atomic.Bool
+}
+
+// newIdleTimeoutReader 创建空闲超时读取器，超时后调用cancel
+func newIdleTimeoutReader(r io.Reader, timeout time.Duration, cancel context.CancelFunc) *idleTimeoutReader {
+	ir := &idleTimeoutReader{r: r, timeout: timeout}
+	ir.timer = time.AfterFunc(timeout, func() {
+		ir.expired.Store(true)
+		cancel()
+	})
+	return ir
+}
+
+// Read 实现io.Reader接口
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+	if err != nil {
+		r.timer.Stop()
+		if r.expired.Load() {
+			err = fmt.Errorf("超过 %v 没有收到数据", r.timeout)
+		}
+	}
+	return n, err
+}
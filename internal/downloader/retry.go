@@ -0,0 +1,93 @@
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 重试间隔
+const (
+	baseBackoff = time.Second
+	maxBackoff  = time.Minute
+	// maxRetryAfter Retry-After的上限，避免服务器返回过长的等待时间
+	maxRetryAfter = 10 * time.Minute
+)
+
+// permanentError 重试也无法成功的错误，如认证失败或文件不存在
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// permanent 将错误标记为不可重试
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// retryAfterError 服务器通过Retry-After指定了重试时间的错误
+type retryAfterError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *retryAfterError) Error() string { return e.err.Error() }
+func (e *retryAfterError) Unwrap() error { return e.err }
+
+// statusError 返回非成功响应的错误信息
+func statusError(prefix string, resp *http.Response) error {
+	var err error
+	if resp.StatusCode == http.StatusUnauthorized {
+		err = fmt.Errorf("%s，认证失败，请检查账号ID和许可证密钥", prefix)
+	} else {
+		err = fmt.Errorf("%s，状态码: %d", prefix, resp.StatusCode)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return permanent(err)
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		if after, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return &retryAfterError{err: err, after: after}
+		}
+	}
+	return err
+}
+
+// parseRetryAfter 解析Retry-After头，支持秒数和HTTP日期两种格式
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	var after time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		after = time.Duration(seconds) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		after = t.Sub(now)
+	} else {
+		return 0, false
+	}
+	return min(max(after, 0), maxRetryAfter), true
+}
+
+// retryDelay 返回第attempt次重试前的等待时间
+//
+// 按指数退避并加入随机抖动，避免多个实例同时重试。服务器指定了Retry-After时使用两者中较长的一个。
+func retryDelay(attempt int, err error) time.Duration {
+	delay := baseBackoff << min(attempt, 16)
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	delay = delay/2 + rand.N(delay/2+1)
+
+	var retryAfter *retryAfterError
+	if errors.As(err, &retryAfter) && retryAfter.after > delay {
+		delay = retryAfter.after
+	}
+	return delay
+}
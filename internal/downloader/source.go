@@ -0,0 +1,185 @@
+package downloader
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"ip-geo/internal/config"
+	"ip-geo/internal/database"
+)
+
+// DefaultMaxMindURL MaxMind官方下载接口地址
+const DefaultMaxMindURL = "https://download.maxmind.com"
+
+// 镜像的选择方式
+const (
+	// StrategySequential 按配置顺序依次尝试
+	StrategySequential = "sequential"
+	// StrategyRace 同时请求所有镜像，按响应先后依次尝试
+	StrategyRace = "race"
+)
+
+// source 数据库文件的下载来源，包含一个或多个镜像
+type source struct {
+	mirrors  []*mirror
+	strategy string
+}
+
+// mirror 下载来源中的一个镜像
+type mirror struct {
+	// url 下载地址，不包含认证信息
+	url string
+	// checksumURL 校验文件地址
+	checksumURL string
+	// format 下载文件的格式
+	format string
+	// member 从压缩包中提取的文件名模式
+	member string
+	// username/password HTTP基本认证，为空时不认证
+	username, password string
+}
+
+// newRequest 创建带认证信息的GET请求
+func (m *mirror) newRequest(rawURL string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if m.username != "" {
+		req.SetBasicAuth(m.username, m.password)
+	}
+	return req, nil
+}
+
+// urlMirror 从普通地址下载，校验文件为地址加.sha256后缀
+func urlMirror(rawURL string) *mirror {
+	return &mirror{
+		url:         rawURL,
+		checksumURL: rawURL + ChecksumSuffix,
+		format:      detectFormat(rawURL),
+		member:      DefaultMember,
+	}
+}
+
+// maxMindMirror 从MaxMind官方接口下载指定版本的数据库
+//
+// 接口返回包含.mmdb文件的tar.gz压缩包，校验文件为压缩包的SHA-256。
+func maxMindMirror(editionID string) (*mirror, error) {
+	cfg := config.GetInstance().Download.MaxMind
+
+	accountID := cfg.AccountID
+	if accountID == 0 {
+		if env := os.Getenv("MAXMIND_ACCOUNT_ID"); env != "" {
+			id, err := strconv.Atoi(env)
+			if err != nil {
+				return nil, fmt.Errorf("无效的MAXMIND_ACCOUNT_ID: %v", err)
+			}
+			accountID = id
+		}
+	}
+	licenseKey := cfg.LicenseKey
+	if licenseKey == "" {
+		licenseKey = os.Getenv("MAXMIND_LICENSE_KEY")
+	}
+	if accountID == 0 || licenseKey == "" {
+		return nil, fmt.Errorf("下载 %s 需要配置MaxMind账号ID和许可证密钥", editionID)
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultMaxMindURL
+	}
+	endpoint := strings.TrimSuffix(baseURL, "/") + "/geoip/databases/" + url.PathEscape(editionID) + "/download"
+
+	return &mirror{
+		url:         endpoint + "?suffix=tar.gz",
+		checksumURL: endpoint + "?suffix=tar.gz.sha256",
+		format:      formatTarGz,
+		member:      DefaultMember,
+		username:    strconv.Itoa(accountID),
+		password:    licenseKey,
+	}, nil
+}
+
+// sources 返回已启用数据库的下载来源，键为文件路径
+//
+// 数据库配置的来源优先于内置的下载地址，两者都没有的数据库和内嵌的数据库不下载。
+// 下载来源配置无效的数据库记录在errs中，不影响其他数据库。
+func sources() (result map[string]*source, errs map[string]error) {
+	result = make(map[string]*source)
+	errs = make(map[string]error)
+	for _, d := range config.GetInstance().EnabledDatabases() {
+		// 内嵌的数据库随程序发布，不需要下载
+		if database.LoadMode(d) == database.LoadModeEmbed {
+			continue
+		}
+		if d.Download == nil {
+			if rawURL, ok := mmdbFiles[d.Path]; ok {
+				result[d.Path] = &source{mirrors: []*mirror{urlMirror(rawURL)}, strategy: StrategySequential}
+			}
+			continue
+		}
+		s, err := newSource(d.Name, d.Download)
+		if err != nil {
+			errs[d.Path] = err
+			continue
+		}
+		result[d.Path] = s
+	}
+	return result, errs
+}
+
+// newSource 根据配置创建数据库的下载来源
+func newSource(name string, src *config.DownloadSource) (*source, error) {
+	s := &source{strategy: StrategySequential}
+	switch {
+	case src.EditionID != "":
+		m, err := maxMindMirror(src.EditionID)
+		if err != nil {
+			return nil, err
+		}
+		s.mirrors = append(s.mirrors, m)
+	case src.URL != "" || len(src.Mirrors) > 0:
+		if src.URL != "" {
+			s.mirrors = append(s.mirrors, urlMirror(src.URL))
+		}
+		for _, rawURL := range src.Mirrors {
+			s.mirrors = append(s.mirrors, urlMirror(rawURL))
+		}
+	default:
+		return nil, fmt.Errorf("数据库 %s 的下载来源需要设置url、mirrors或edition_id", name)
+	}
+	for _, m := range s.mirrors {
+		if src.Format != "" {
+			if !validFormat(src.Format) {
+				return nil, fmt.Errorf("数据库 %s 的下载格式 %s 无效", name, src.Format)
+			}
+			m.format = src.Format
+		}
+		if src.Member != "" {
+			m.member = src.Member
+		}
+	}
+	switch src.Strategy {
+	case "", StrategySequential:
+	case StrategyRace:
+		s.strategy = StrategyRace
+	default:
+		return nil, fmt.Errorf("数据库 %s 的镜像选择方式 %s 无效", name, src.Strategy)
+	}
+	return s, nil
+}
+
+// lookupSource 返回数据库文件的下载来源，只返回该数据库自身的配置错误
+func lookupSource(path string) (*source, bool, error) {
+	all, errs := sources()
+	if err, ok := errs[path]; ok {
+		return nil, false, err
+	}
+	s, ok := all[path]
+	return s, ok, nil
+}
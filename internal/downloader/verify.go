@@ -0,0 +1,129 @@
+package downloader
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"ip-geo/internal/config"
+	"ip-geo/internal/logger"
+	"ip-geo/internal/provider"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// ChecksumSuffix 校验文件的后缀，与MaxMind的约定一致
+const ChecksumSuffix = ".sha256"
+
+// checksumTimeout 下载校验文件的超时时间
+const checksumTimeout = 30 * time.Second
+
+// fetchChecksum 下载镜像的校验文件，不存在时返回空字符串
+//
+// 校验文件的内容为sha256sum的输出格式，即"<十六进制摘要>  <文件名>"，只取第一个字段。
+// 压缩包的校验和针对压缩包本身。内容不是校验和时（如镜像返回的HTML页面），
+// 要求校验和时返回不可重试的错误，否则与校验文件不存在相同。
+func fetchChecksum(client *http.Client, m *mirror) (string, error) {
+	req, err := m.newRequest(m.checksumURL)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), checksumTimeout)
+	defer cancel()
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("下载校验文件失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", nil
+	default:
+		return "", statusError("下载校验文件失败", resp)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	var sum string
+	var invalid error
+	switch {
+	case scanner.Scan():
+		sum, invalid = parseChecksum(scanner.Text())
+	case errors.Is(scanner.Err(), bufio.ErrTooLong):
+		invalid = fmt.Errorf("校验文件的第一行过长")
+	case scanner.Err() != nil:
+		return "", fmt.Errorf("读取校验文件失败: %v", scanner.Err())
+	default:
+		invalid = fmt.Errorf("校验文件为空")
+	}
+	if invalid != nil {
+		if config.GetInstance().Download.RequireChecksum {
+			return "", permanent(fmt.Errorf("%s: %v", m.checksumURL, invalid))
+		}
+		logger.Warn("%s 不是有效的校验文件: %v", m.checksumURL, invalid)
+		return "", nil
+	}
+	return sum, nil
+}
+
+// parseChecksum 从校验文件的第一行中取出SHA-256校验和
+func parseChecksum(line string) (string, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("校验文件为空")
+	}
+	sum := strings.ToLower(fields[0])
+	if b, err := hex.DecodeString(sum); err != nil || len(b) != 32 {
+		return "", fmt.Errorf("无效的SHA-256校验和: %q", fields[0])
+	}
+	return sum, nil
+}
+
+// verifyDatabase 在替换当前数据库之前检查下载的文件
+//
+// 检查文件结构，并拒绝格式与当前数据库不同或构建时间早于当前数据库的文件。
+func verifyDatabase(file, current string) error {
+	reader, err := maxminddb.Open(file)
+	if err != nil {
+		return fmt.Errorf("无效的数据库文件: %v", err)
+	}
+	defer reader.Close()
+
+	if err := reader.Verify(); err != nil {
+		return fmt.Errorf("数据库结构校验失败: %v", err)
+	}
+
+	if !fileExists(current) {
+		return nil
+	}
+	currentReader, err := maxminddb.Open(current)
+	if err != nil {
+		// 当前文件已损坏时允许替换
+		logger.Warn("打开当前数据库 %s 失败: %v", current, err)
+		return nil
+	}
+	defer currentReader.Close()
+
+	// 允许更换为同一格式的其他数据库，如GeoIP2-City更换为GeoLite2-City
+	got, want := reader.Metadata.DatabaseType, currentReader.Metadata.DatabaseType
+	gotSchema, _ := provider.DetectSchema(got)
+	wantSchema, _ := provider.DetectSchema(want)
+	if got != want && (gotSchema == "" || gotSchema != wantSchema) {
+		return permanent(fmt.Errorf("数据库类型 %s 与当前数据库 %s 不一致", got, want))
+	}
+	if reader.Metadata.BuildEpoch < currentReader.Metadata.BuildEpoch {
+		got := time.Unix(int64(reader.Metadata.BuildEpoch), 0).UTC()
+		want := time.Unix(int64(currentReader.Metadata.BuildEpoch), 0).UTC()
+		if !config.GetInstance().Download.AllowOlder {
+			return permanent(fmt.Errorf("构建时间 %s 早于当前数据库 %s", got.Format(time.RFC3339), want.Format(time.RFC3339)))
+		}
+		logger.Warn("%s 的构建时间 %s 早于当前数据库 %s", current, got.Format(time.RFC3339), want.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ip-geo/internal/config"
+)
+
+func TestFetchChecksum(t *testing.T) {
+	sum := strings.Repeat("ab", 32)
+	bodies := map[string]string{
+		"/valid.mmdb.sha256": fmt.Sprintf("%s  valid.mmdb\n", strings.ToUpper(sum)),
+		// 镜像对不存在的文件返回200和HTML页面
+		"/html.mmdb.sha256":  "<!DOCTYPE html>\n<html><body>Not Found</body></html>\n",
+		"/empty.mmdb.sha256": "",
+		"/long.mmdb.sha256":  strings.Repeat("x", 128*1024),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := bodies[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+
+	cfg := config.GetInstance()
+	oldDownload := cfg.Download
+	t.Cleanup(func() { cfg.Download = oldDownload })
+
+	tests := []struct {
+		file    string
+		require bool
+		want    string
+		// permanent 为true时应当返回不可重试的错误
+		permanent bool
+	}{
+		{file: "valid.mmdb", want: sum},
+		{file: "valid.mmdb", require: true, want: sum},
+		{file: "missing.mmdb"},
+		{file: "html.mmdb"},
+		{file: "html.mmdb", require: true, permanent: true},
+		{file: "empty.mmdb"},
+		{file: "empty.mmdb", require: true, permanent: true},
+		{file: "long.mmdb"},
+		{file: "long.mmdb", require: true, permanent: true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/require=%v", tt.file, tt.require), func(t *testing.T) {
+			cfg.Download.RequireChecksum = tt.require
+			got, err := fetchChecksum(srv.Client(), urlMirror(srv.URL+"/"+tt.file))
+			var perm *permanentError
+			if tt.permanent {
+				if !errors.As(err, &perm) {
+					t.Fatalf("fetchChecksum error = %v, want permanent error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fetchChecksum: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("fetchChecksum = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+// Package embedded 提供编译时内嵌到程序中的数据库文件
+//
+// 使用embed构建标签编译时，internal/embedded/mmdb目录下的文件会内嵌到程序中，
+// 加载方式为embed的数据库按文件名从中读取，适用于无法访问网络的单文件部署：
+//
+//	cp mmdb/*.mmdb internal/embedded/mmdb/
+//	go build -tags embed ./cmd/server
+package embedded
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+)
+
+// ErrNotEmbedded 编译时没有使用embed构建标签
+var ErrNotEmbedded = errors.New("程序编译时没有使用embed构建标签，不包含内嵌的数据库")
+
+// ReadFile 读取内嵌的数据库文件，name为文件名
+func ReadFile(name string) ([]byte, error) {
+	if files == nil {
+		return nil, ErrNotEmbedded
+	}
+	return fs.ReadFile(files, path.Join("mmdb", name))
+}
@@ -0,0 +1,14 @@
+//go:build embed
+
+package embedded
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:mmdb
+var mmdbFiles embed.FS
+
+// files 内嵌的文件
+var files fs.FS = mmdbFiles
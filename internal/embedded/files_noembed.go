@@ -0,0 +1,8 @@
+//go:build !embed
+
+package embedded
+
+import "io/fs"
+
+// files 没有使用embed构建标签时不内嵌文件
+var files fs.FS
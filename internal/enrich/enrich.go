@@ -0,0 +1,224 @@
+// Package enrich 实现日志文件的批量地理信息富化
+package enrich
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+
+	"ip-geo/internal/logger"
+	"ip-geo/internal/service"
+)
+
+// maxLineSize 单行最大长度
+const maxLineSize = 1024 * 1024
+
+// Options 富化任务选项
+type Options struct {
+	// Extractor 从每行中提取IP的方式
+	Extractor Extractor
+	// Fields 追加到每行后的地理信息列
+	Fields []string
+	// Format 输出格式: csv、tsv或jsonl
+	Format string
+	// Workers 并发查询的协程数，默认为CPU核数
+	Workers int
+	// Header 第一行是否为表头
+	Header bool
+}
+
+// Stats 富化任务统计
+type Stats struct {
+	Lines    int64
+	Enriched int64
+	NoIP     int64
+	Failed   int64
+	Elapsed  time.Duration
+}
+
+// String 返回统计信息摘要
+func (s *Stats) String() string {
+	rate := float64(0)
+	if s.Elapsed > 0 {
+		rate = float64(s.Lines) / s.Elapsed.Seconds()
+	}
+	return fmt.Sprintf("共处理 %d 行，富化 %d 行，未找到IP %d 行，查询失败 %d 行，耗时 %s，%.0f 行/秒",
+		s.Lines, s.Enriched, s.NoIP, s.Failed, s.Elapsed.Round(time.Millisecond), rate)
+}
+
+// 单行的处理结果
+const (
+	statusEnriched = iota
+	statusNoIP
+	statusFailed
+	statusHeader
+)
+
+// job 单行处理任务，结果通过result按输入顺序交给写入方
+type job struct {
+	line   string
+	header bool
+	result chan result
+}
+
+// result 单行处理结果
+type result struct {
+	text   string
+	status int
+	err    error
+}
+
+// Enricher 日志富化器
+type Enricher struct {
+	ipService *service.IPService
+	opts      Options
+	formatter formatter
+}
+
+// NewEnricher 创建新的Enricher实例
+func NewEnricher(ipService *service.IPService, opts Options) (*Enricher, error) {
+	if opts.Extractor == nil {
+		opts.Extractor = &ColumnExtractor{}
+	}
+	if len(opts.Fields) == 0 {
+		opts.Fields = DefaultFields
+	}
+	for _, field := range opts.Fields {
+		if _, ok := fieldGetters[field]; !ok {
+			return nil, fmt.Errorf("未知的输出列: %s", field)
+		}
+	}
+	if opts.Format == "" {
+		opts.Format = FormatCSV
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+
+	f, err := newFormatter(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Enricher{
+		ipService: ipService,
+		opts:      opts,
+		formatter: f,
+	}, nil
+}
+
+// Run 从r逐行读取，富化后按原顺序写入w
+func (e *Enricher) Run(r io.Reader, w io.Writer) (*Stats, error) {
+	start := time.Now()
+	stats := &Stats{}
+
+	jobs := make(chan *job, e.opts.Workers*4)
+	order := make(chan *job, e.opts.Workers*16)
+	readErr := make(chan error, 1)
+
+	// 读取输入
+	go func() {
+		defer close(jobs)
+		defer close(order)
+
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+		first := true
+		for scanner.Scan() {
+			j := &job{
+				line:   scanner.Text(),
+				header: first && e.opts.Header,
+				result: make(chan result, 1),
+			}
+			first = false
+			order <- j
+			jobs <- j
+		}
+		readErr <- scanner.Err()
+	}()
+
+	// 启动查询协程
+	for i := 0; i < e.opts.Workers; i++ {
+		go func() {
+			for j := range jobs {
+				j.result <- e.process(j)
+			}
+		}()
+	}
+
+	// 按输入顺序写出结果
+	bw := bufio.NewWriter(w)
+	var writeErr error
+	for j := range order {
+		res := <-j.result
+		if writeErr != nil {
+			continue
+		}
+		if res.err != nil {
+			writeErr = res.err
+			continue
+		}
+
+		stats.Lines++
+		switch res.status {
+		case statusEnriched:
+			stats.Enriched++
+		case statusNoIP:
+			stats.NoIP++
+		case statusFailed:
+			stats.Failed++
+		}
+
+		if _, err := bw.WriteString(res.text); err != nil {
+			writeErr = err
+			continue
+		}
+		if err := bw.WriteByte('\n'); err != nil {
+			writeErr = err
+		}
+	}
+
+	if writeErr == nil {
+		writeErr = bw.Flush()
+	}
+	stats.Elapsed = time.Since(start)
+
+	if err := <-readErr; err != nil {
+		return stats, fmt.Errorf("读取输入失败: %v", err)
+	}
+	if writeErr != nil {
+		return stats, fmt.Errorf("写入输出失败: %v", writeErr)
+	}
+	return stats, nil
+}
+
+// process 处理单行
+func (e *Enricher) process(j *job) result {
+	if j.header {
+		text, err := e.formatter.header(j.line, e.opts.Fields)
+		return result{text: text, status: statusHeader, err: err}
+	}
+
+	values := make([]string, len(e.opts.Fields))
+	ip, ok := e.opts.Extractor.Extract(j.line)
+	if !ok {
+		text, err := e.formatter.format(j.line, "", e.opts.Fields, values)
+		return result{text: text, status: statusNoIP, err: err}
+	}
+
+	status := statusEnriched
+	resp, err := e.ipService.LookupIP(ip)
+	if err != nil {
+		logger.Warn("富化查询IP失败 %s: %v", ip, err)
+		status = statusFailed
+	} else {
+		for i, field := range e.opts.Fields {
+			values[i] = fieldGetters[field](resp)
+		}
+	}
+
+	text, err := e.formatter.format(j.line, ip, e.opts.Fields, values)
+	return result{text: text, status: status, err: err}
+}
@@ -0,0 +1,100 @@
+package enrich
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// Extractor 从日志行中提取IP地址
+type Extractor interface {
+	Extract(line string) (string, bool)
+}
+
+// ColumnExtractor 按列序号提取IP
+type ColumnExtractor struct {
+	// Index 列序号，从0开始
+	Index int
+	// Delimiter 列分隔符，为空时按空白字符分割
+	Delimiter string
+}
+
+// Extract 实现Extractor接口
+func (e *ColumnExtractor) Extract(line string) (string, bool) {
+	var columns []string
+	switch e.Delimiter {
+	case "":
+		columns = strings.Fields(line)
+	case ",", "\t":
+		// CSV/TSV需要处理引号包裹的字段
+		reader := csv.NewReader(strings.NewReader(line))
+		reader.Comma = rune(e.Delimiter[0])
+		reader.LazyQuotes = true
+		reader.FieldsPerRecord = -1
+		record, err := reader.Read()
+		if err != nil {
+			return "", false
+		}
+		columns = record
+	default:
+		columns = strings.Split(line, e.Delimiter)
+	}
+
+	if e.Index < 0 || e.Index >= len(columns) {
+		return "", false
+	}
+	return normalizeIP(columns[e.Index])
+}
+
+// RegexExtractor 按正则表达式提取IP
+//
+// 优先使用名为ip的捕获组，其次使用第一个捕获组，都没有时使用整个匹配。
+type RegexExtractor struct {
+	re    *regexp.Regexp
+	group int
+}
+
+// NewRegexExtractor 创建新的RegexExtractor实例
+func NewRegexExtractor(pattern string) (*RegexExtractor, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("无效的正则表达式: %v", err)
+	}
+
+	group := 0
+	if index := re.SubexpIndex("ip"); index > 0 {
+		group = index
+	} else if re.NumSubexp() > 0 {
+		group = 1
+	}
+
+	return &RegexExtractor{re: re, group: group}, nil
+}
+
+// Extract 实现Extractor接口
+func (e *RegexExtractor) Extract(line string) (string, bool) {
+	match := e.re.FindStringSubmatch(line)
+	if match == nil || match[e.group] == "" {
+		return "", false
+	}
+	return normalizeIP(match[e.group])
+}
+
+// normalizeIP 清理提取到的值，去除引号、方括号和端口号
+func normalizeIP(value string) (string, bool) {
+	value = strings.Trim(strings.TrimSpace(value), `"'`)
+	if net.ParseIP(value) != nil {
+		return value, true
+	}
+
+	if host, _, err := net.SplitHostPort(value); err == nil && net.ParseIP(host) != nil {
+		return host, true
+	}
+
+	if trimmed := strings.Trim(value, "[]"); net.ParseIP(trimmed) != nil {
+		return trimmed, true
+	}
+	return "", false
+}
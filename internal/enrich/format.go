@@ -0,0 +1,141 @@
+package enrich
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ip-geo/internal/api/response"
+)
+
+// 输出格式
+const (
+	FormatCSV   = "csv"
+	FormatTSV   = "tsv"
+	FormatJSONL = "jsonl"
+)
+
+// DefaultFields 默认输出的地理信息列
+var DefaultFields = []string{"country_code", "country", "region", "city", "asn", "isp"}
+
+// fieldGetters 可输出的列及其取值方法
+var fieldGetters = map[string]func(*response.IPResponse) string{
+	"ip":             func(r *response.IPResponse) string { return r.IP },
+	"version":        func(r *response.IPResponse) string { return r.Version },
+	"asn":            func(r *response.IPResponse) string { return formatUint(uint64(r.ASN.Number)) },
+	"asn_name":       func(r *response.IPResponse) string { return r.ASN.Name },
+	"asn_info":       func(r *response.IPResponse) string { return r.ASN.Info },
+	"network":        func(r *response.IPResponse) string { return r.Network.CIDR },
+	"network_type":   func(r *response.IPResponse) string { return r.Network.Type },
+	"continent_code": func(r *response.IPResponse) string { return r.Location.Continent.Code },
+	"continent":      func(r *response.IPResponse) string { return r.Location.Continent.Name },
+	"country_code":   func(r *response.IPResponse) string { return r.Location.Country.Code },
+	"country":        func(r *response.IPResponse) string { return r.Location.Country.Name },
+	"region_code":    func(r *response.IPResponse) string { return r.Location.Region.Code },
+	"region":         func(r *response.IPResponse) string { return r.Location.Region.Name },
+	"city":           func(r *response.IPResponse) string { return r.Location.City.Name },
+	"latitude":       func(r *response.IPResponse) string { return formatFloat(r.Location.Location.Latitude) },
+	"longitude":      func(r *response.IPResponse) string { return formatFloat(r.Location.Location.Longitude) },
+	"timezone":       func(r *response.IPResponse) string { return r.Location.Location.TimeZone },
+	"isp":            func(r *response.IPResponse) string { return r.ISP.Name },
+	"isp_type":       func(r *response.IPResponse) string { return r.ISP.Type },
+}
+
+// formatter 将原始行和地理信息列组合为输出行
+type formatter interface {
+	header(line string, fields []string) (string, error)
+	format(line, ip string, fields, values []string) (string, error)
+}
+
+// newFormatter 根据格式名称创建formatter
+func newFormatter(format string) (formatter, error) {
+	switch format {
+	case FormatCSV:
+		return &delimitedFormatter{comma: ','}, nil
+	case FormatTSV:
+		return &delimitedFormatter{comma: '\t'}, nil
+	case FormatJSONL:
+		return &jsonlFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("不支持的输出格式: %s", format)
+	}
+}
+
+// delimitedFormatter 输出CSV/TSV，原始行保持不变，地理信息追加在行尾
+type delimitedFormatter struct {
+	comma rune
+}
+
+// header 为表头行追加列名
+func (f *delimitedFormatter) header(line string, fields []string) (string, error) {
+	return f.format(line, "", fields, fields)
+}
+
+// format 实现formatter接口
+func (f *delimitedFormatter) format(line, ip string, fields, values []string) (string, error) {
+	if f.comma == '\t' {
+		// TSV不支持引号转义，直接替换值中的特殊字符
+		cleaned := make([]string, len(values))
+		for i, value := range values {
+			cleaned[i] = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ").Replace(value)
+		}
+		return line + "\t" + strings.Join(cleaned, "\t"), nil
+	}
+
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	w.Comma = f.comma
+	if err := w.Write(values); err != nil {
+		return "", err
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+	return line + string(f.comma) + strings.TrimRight(buf.String(), "\r\n"), nil
+}
+
+// jsonlFormatter 输出JSON Lines，原始行放在line字段中
+type jsonlFormatter struct{}
+
+// header JSON Lines没有表头，表头行按普通行输出
+func (f *jsonlFormatter) header(line string, fields []string) (string, error) {
+	return f.format(line, "", nil, nil)
+}
+
+// format 实现formatter接口
+func (f *jsonlFormatter) format(line, ip string, fields, values []string) (string, error) {
+	record := make(map[string]string, len(fields)+2)
+	record["line"] = line
+	if ip != "" {
+		record["ip"] = ip
+	}
+	for i, field := range fields {
+		record[field] = values[i]
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// formatUint 格式化整数，0输出为空
+func formatUint(v uint64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatUint(v, 10)
+}
+
+// formatFloat 格式化浮点数，0输出为空
+func formatFloat(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
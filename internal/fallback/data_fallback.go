@@ -0,0 +1,13 @@
+//go:build fallback
+
+package fallback
+
+import _ "embed"
+
+// bundled 使用fallback构建标签编译
+const bundled = true
+
+// data 内嵌的国家级数据库，仓库中的country.mmdb是空的占位文件
+//
+//go:embed country.mmdb
+var data []byte
@@ -0,0 +1,9 @@
+//go:build !fallback
+
+package fallback
+
+// bundled 使用fallback构建标签编译
+const bundled = false
+
+// data 没有使用fallback构建标签时不内嵌数据库
+var data []byte
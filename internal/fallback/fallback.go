@@ -0,0 +1,32 @@
+// Package fallback 提供编译时内嵌的国家级数据库
+//
+// 使用fallback构建标签编译时，internal/fallback/country.mmdb会内嵌到程序中，
+// 在没有任何数据库文件的离线环境中仍然可以查询洲和国家。仓库中的country.mmdb是空的占位文件，
+// 编译前需要先由cmd/fallback-build生成：
+//
+//	go run ./cmd/fallback-build -city mmdb/GeoLite2-City.mmdb
+//	go build -tags fallback ./cmd/server
+package fallback
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// ErrNotBundled 程序不包含内嵌的国家级数据库
+var ErrNotBundled = errors.New("程序不包含内嵌的国家级数据库")
+
+// Open 打开内嵌的国家级数据库
+//
+// 没有使用fallback构建标签，或者编译前没有生成数据库而内嵌了空的占位文件时，返回的错误为ErrNotBundled。
+func Open() (*maxminddb.Reader, error) {
+	if len(data) == 0 {
+		if bundled {
+			return nil, fmt.Errorf("%w: 内嵌的country.mmdb是空的占位文件，使用fallback构建标签编译前需要先运行 go run ./cmd/fallback-build 生成", ErrNotBundled)
+		}
+		return nil, ErrNotBundled
+	}
+	return maxminddb.FromBytes(data)
+}
@@ -0,0 +1,26 @@
+package fallback
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestOpen 检查内嵌的数据库可以打开，或者没有内嵌数据库时返回ErrNotBundled
+//
+// 没有使用fallback构建标签，或者使用构建标签但仓库中只有空的占位文件时都应当返回ErrNotBundled。
+func TestOpen(t *testing.T) {
+	reader, err := Open()
+	if len(data) == 0 {
+		if !errors.Is(err, ErrNotBundled) {
+			t.Fatalf("Open() error = %v, want ErrNotBundled", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer reader.Close()
+	if err := reader.Verify(); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package grpcapi
+
+import (
+	"time"
+
+	"ip-geo/internal/api/response"
+	"ip-geo/internal/grpcapi/ipgeopb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// toIPResponse 将HTTP接口的响应转换为protobuf消息
+func toIPResponse(r *response.IPResponse) *ipgeopb.IPResponse {
+	loc := &r.Location
+	msg := &ipgeopb.IPResponse{
+		Ip:      r.IP,
+		Version: r.Version,
+		Asn: &ipgeopb.ASN{
+			Number: uint32(r.ASN.Number),
+			Name:   r.ASN.Name,
+			Info:   r.ASN.Info,
+		},
+		Network: &ipgeopb.Network{
+			Cidr:     r.Network.CIDR,
+			StartIp:  r.Network.StartIP,
+			EndIp:    r.Network.EndIP,
+			TotalIps: r.Network.TotalIPs,
+			Type:     r.Network.Type,
+		},
+		Location: &ipgeopb.Location{
+			Continent: &ipgeopb.CodeName{Code: loc.Continent.Code, Name: loc.Continent.Name},
+			Country:   &ipgeopb.CodeName{Code: loc.Country.Code, Name: loc.Country.Name},
+			Region:    &ipgeopb.CodeName{Code: loc.Region.Code, Name: loc.Region.Name},
+			City:      &ipgeopb.City{Name: loc.City.Name},
+			Location: &ipgeopb.Coordinates{
+				Latitude:       loc.Location.Latitude,
+				Longitude:      loc.Location.Longitude,
+				AccuracyRadius: uint32(loc.Location.AccuracyRadius),
+				Timezone:       loc.Location.TimeZone,
+			},
+		},
+		Isp: &ipgeopb.ISP{
+			Name: r.ISP.Name,
+			Type: r.ISP.Type,
+		},
+		Security: &ipgeopb.Security{
+			IsAnonymous:        r.Security.IsAnonymous,
+			IsAnonymousVpn:     r.Security.IsAnonymousVPN,
+			IsHostingProvider:  r.Security.IsHostingProvider,
+			IsPublicProxy:      r.Security.IsPublicProxy,
+			IsResidentialProxy: r.Security.IsResidentialProxy,
+			IsTorExitNode:      r.Security.IsTorExitNode,
+		},
+		Tags:               r.Tags,
+		Overrides:          r.Overrides,
+		Fallback:           r.Fallback,
+		SourcesUnavailable: r.SourcesUnavailable,
+	}
+	if len(r.Security.Sources) > 0 {
+		msg.Security.Sources = make(map[string]*ipgeopb.Sources, len(r.Security.Sources))
+		for flag, names := range r.Security.Sources {
+			msg.Security.Sources[flag] = &ipgeopb.Sources{Names: names}
+		}
+	}
+	return msg
+}
+
+// toDatabaseInfo 将数据库状态转换为protobuf消息
+func toDatabaseInfo(s *response.DatabaseStatus) *ipgeopb.DatabaseInfo {
+	return &ipgeopb.DatabaseInfo{
+		Name:         s.Name,
+		Role:         s.Role,
+		Path:         s.Path,
+		Required:     s.Required,
+		Loaded:       s.Loaded,
+		Size:         s.Size,
+		Sha256:       s.SHA256,
+		DatabaseType: s.DatabaseType,
+		BuildEpoch:   uint64(s.BuildEpoch),
+		BuildTime:    toTimestamp(s.BuildTime),
+		Languages:    s.Languages,
+		LoadMode:     s.LoadMode,
+		Memory:       s.Memory,
+		DownloadedAt: toTimestamp(derefTime(s.DownloadedAt)),
+		SourceUrl:    s.SourceURL,
+		Version:      s.Version,
+		Versions:     int32(s.Versions),
+		Error:        s.Error,
+	}
+}
+
+// toTimestamp 转换时间，零值返回nil
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// derefTime 返回指针指向的时间，nil返回零值
+func derefTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
@@ -0,0 +1,241 @@
+// IP地理位置查询的gRPC接口，消息结构与HTTP接口的JSON响应一致
+//
+// 修改后在本目录执行以下命令重新生成代码:
+//   protoc --go_out=. --go_opt=paths=source_relative \
+//     --go-grpc_out=. --go-grpc_opt=paths=source_relative ipgeo.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: ipgeo.proto
+
+package ipgeopb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	IPGeo_Lookup_FullMethodName          = "/ipgeo.v1.IPGeo/Lookup"
+	IPGeo_BatchLookup_FullMethodName     = "/ipgeo.v1.IPGeo/BatchLookup"
+	IPGeo_StreamLookup_FullMethodName    = "/ipgeo.v1.IPGeo/StreamLookup"
+	IPGeo_GetDatabaseInfo_FullMethodName = "/ipgeo.v1.IPGeo/GetDatabaseInfo"
+)
+
+// IPGeoClient is the client API for IPGeo service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// IPGeo IP地理位置查询服务
+type IPGeoClient interface {
+	// Lookup 查询单个IP
+	Lookup(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*IPResponse, error)
+	// BatchLookup 客户端流式发送IP，结束后一次返回所有结果
+	BatchLookup(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[LookupRequest, BatchLookupResponse], error)
+	// StreamLookup 双向流式查询，每收到一个IP返回一个结果
+	StreamLookup(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[LookupRequest, LookupResult], error)
+	// GetDatabaseInfo 返回数据库的状态，name为空时返回所有已配置的数据库
+	GetDatabaseInfo(ctx context.Context, in *GetDatabaseInfoRequest, opts ...grpc.CallOption) (*GetDatabaseInfoResponse, error)
+}
+
+type iPGeoClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIPGeoClient(cc grpc.ClientConnInterface) IPGeoClient {
+	return &iPGeoClient{cc}
+}
+
+func (c *iPGeoClient) Lookup(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*IPResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IPResponse)
+	err := c.cc.Invoke(ctx, IPGeo_Lookup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPGeoClient) BatchLookup(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[LookupRequest, BatchLookupResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &IPGeo_ServiceDesc.Streams[0], IPGeo_BatchLookup_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[LookupRequest, BatchLookupResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IPGeo_BatchLookupClient = grpc.ClientStreamingClient[LookupRequest, BatchLookupResponse]
+
+func (c *iPGeoClient) StreamLookup(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[LookupRequest, LookupResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &IPGeo_ServiceDesc.Streams[1], IPGeo_StreamLookup_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[LookupRequest, LookupResult]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IPGeo_StreamLookupClient = grpc.BidiStreamingClient[LookupRequest, LookupResult]
+
+func (c *iPGeoClient) GetDatabaseInfo(ctx context.Context, in *GetDatabaseInfoRequest, opts ...grpc.CallOption) (*GetDatabaseInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDatabaseInfoResponse)
+	err := c.cc.Invoke(ctx, IPGeo_GetDatabaseInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IPGeoServer is the server API for IPGeo service.
+// All implementations must embed UnimplementedIPGeoServer
+// for forward compatibility.
+//
+// IPGeo IP地理位置查询服务
+type IPGeoServer interface {
+	// Lookup 查询单个IP
+	Lookup(context.Context, *LookupRequest) (*IPResponse, error)
+	// BatchLookup 客户端流式发送IP，结束后一次返回所有结果
+	BatchLookup(grpc.ClientStreamingServer[LookupRequest, BatchLookupResponse]) error
+	// StreamLookup 双向流式查询，每收到一个IP返回一个结果
+	StreamLookup(grpc.BidiStreamingServer[LookupRequest, LookupResult]) error
+	// GetDatabaseInfo 返回数据库的状态，name为空时返回所有已配置的数据库
+	GetDatabaseInfo(context.Context, *GetDatabaseInfoRequest) (*GetDatabaseInfoResponse, error)
+	mustEmbedUnimplementedIPGeoServer()
+}
+
+// UnimplementedIPGeoServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedIPGeoServer struct{}
+
+func (UnimplementedIPGeoServer) Lookup(context.Context, *LookupRequest) (*IPResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Lookup not implemented")
+}
+func (UnimplementedIPGeoServer) BatchLookup(grpc.ClientStreamingServer[LookupRequest, BatchLookupResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method BatchLookup not implemented")
+}
+func (UnimplementedIPGeoServer) StreamLookup(grpc.BidiStreamingServer[LookupRequest, LookupResult]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLookup not implemented")
+}
+func (UnimplementedIPGeoServer) GetDatabaseInfo(context.Context, *GetDatabaseInfoRequest) (*GetDatabaseInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDatabaseInfo not implemented")
+}
+func (UnimplementedIPGeoServer) mustEmbedUnimplementedIPGeoServer() {}
+func (UnimplementedIPGeoServer) testEmbeddedByValue()               {}
+
+// UnsafeIPGeoServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IPGeoServer will
+// result in compilation errors.
+type UnsafeIPGeoServer interface {
+	mustEmbedUnimplementedIPGeoServer()
+}
+
+func RegisterIPGeoServer(s grpc.ServiceRegistrar, srv IPGeoServer) {
+	// If the following call pancis, it indicates UnimplementedIPGeoServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&IPGeo_ServiceDesc, srv)
+}
+
+func _IPGeo_Lookup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPGeoServer).Lookup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPGeo_Lookup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPGeoServer).Lookup(ctx, req.(*LookupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPGeo_BatchLookup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IPGeoServer).BatchLookup(&grpc.GenericServerStream[LookupRequest, BatchLookupResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IPGeo_BatchLookupServer = grpc.ClientStreamingServer[LookupRequest, BatchLookupResponse]
+
+func _IPGeo_StreamLookup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IPGeoServer).StreamLookup(&grpc.GenericServerStream[LookupRequest, LookupResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IPGeo_StreamLookupServer = grpc.BidiStreamingServer[LookupRequest, LookupResult]
+
+func _IPGeo_GetDatabaseInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDatabaseInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPGeoServer).GetDatabaseInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPGeo_GetDatabaseInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPGeoServer).GetDatabaseInfo(ctx, req.(*GetDatabaseInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// IPGeo_ServiceDesc is the grpc.ServiceDesc for IPGeo service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IPGeo_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ipgeo.v1.IPGeo",
+	HandlerType: (*IPGeoServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Lookup",
+			Handler:    _IPGeo_Lookup_Handler,
+		},
+		{
+			MethodName: "GetDatabaseInfo",
+			Handler:    _IPGeo_GetDatabaseInfo_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BatchLookup",
+			Handler:       _IPGeo_BatchLookup_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "StreamLookup",
+			Handler:       _IPGeo_StreamLookup_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "ipgeo.proto",
+}
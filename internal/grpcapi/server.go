@@ -0,0 +1,169 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"ip-geo/internal/api/response"
+	"ip-geo/internal/config"
+	"ip-geo/internal/grpcapi/ipgeopb"
+	"ip-geo/internal/logger"
+	"ip-geo/internal/server"
+	"ip-geo/internal/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// defaultMaxBatchSize BatchLookup默认一次最多查询的IP数
+const defaultMaxBatchSize = 1000
+
+// IPService gRPC服务使用的查询接口，由*service.IPService实现
+type IPService interface {
+	LookupIP(ip string) (*response.IPResponse, error)
+	DatabaseStatus(name string) (*response.DatabaseStatus, error)
+	DatabaseStatuses() []*response.DatabaseStatus
+}
+
+// Server 实现ipgeopb.IPGeoServer，与HTTP接口使用同一个IPService
+type Server struct {
+	ipgeopb.UnimplementedIPGeoServer
+
+	ipService IPService
+	// maxBatchSize BatchLookup一次最多查询的IP数
+	maxBatchSize int
+}
+
+// NewServer 创建gRPC服务，maxBatchSize不大于0时使用默认值
+func NewServer(ipService IPService, maxBatchSize int) *Server {
+	if maxBatchSize <= 0 {
+		maxBatchSize = defaultMaxBatchSize
+	}
+	return &Server{
+		ipService:    ipService,
+		maxBatchSize: maxBatchSize,
+	}
+}
+
+// Lookup 查询单个IP
+func (s *Server) Lookup(ctx context.Context, req *ipgeopb.LookupRequest) (*ipgeopb.IPResponse, error) {
+	resp, err := s.ipService.LookupIP(req.GetIp())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toIPResponse(resp), nil
+}
+
+// BatchLookup 接收客户端发送的所有IP后一次返回结果，单个IP查询失败不影响其他IP
+func (s *Server) BatchLookup(stream grpc.ClientStreamingServer[ipgeopb.LookupRequest, ipgeopb.BatchLookupResponse]) error {
+	var results []*ipgeopb.LookupResult
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&ipgeopb.BatchLookupResponse{Results: results})
+		}
+		if err != nil {
+			return err
+		}
+		if len(results) >= s.maxBatchSize {
+			return status.Errorf(codes.ResourceExhausted, "一次最多查询 %d 个IP", s.maxBatchSize)
+		}
+		results = append(results, s.lookup(req.GetIp()))
+	}
+}
+
+// StreamLookup 每收到一个IP返回一个结果，单个IP查询失败不会结束流
+func (s *Server) StreamLookup(stream grpc.BidiStreamingServer[ipgeopb.LookupRequest, ipgeopb.LookupResult]) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(s.lookup(req.GetIp())); err != nil {
+			return err
+		}
+	}
+}
+
+// GetDatabaseInfo 返回数据库的状态，name为空时返回所有已配置的数据库
+func (s *Server) GetDatabaseInfo(ctx context.Context, req *ipgeopb.GetDatabaseInfoRequest) (*ipgeopb.GetDatabaseInfoResponse, error) {
+	resp := &ipgeopb.GetDatabaseInfoResponse{}
+	if req.GetName() != "" {
+		st, err := s.ipService.DatabaseStatus(req.GetName())
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		resp.Databases = append(resp.Databases, toDatabaseInfo(st))
+		return resp, nil
+	}
+	for _, st := range s.ipService.DatabaseStatuses() {
+		resp.Databases = append(resp.Databases, toDatabaseInfo(st))
+	}
+	return resp, nil
+}
+
+// lookup 查询单个IP，错误记录在结果中
+func (s *Server) lookup(ip string) *ipgeopb.LookupResult {
+	result := &ipgeopb.LookupResult{Ip: ip}
+	resp, err := s.ipService.LookupIP(ip)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Response = toIPResponse(resp)
+	return result
+}
+
+// toStatus 将服务层的错误转换为gRPC状态
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidIP):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrUnknownDatabase):
+		return status.Error(codes.NotFound, err.Error())
+	}
+	logger.Error("gRPC请求失败: %v", err)
+	return status.Error(codes.Internal, "服务器内部错误")
+}
+
+// NewGRPCServer 根据配置创建注册了IPGeo服务的gRPC服务器
+func NewGRPCServer(cfg *config.GRPCConfig, ipService *service.IPService) (*grpc.Server, error) {
+	var opts []grpc.ServerOption
+	if cfg.TLS.Enabled() {
+		tlsConfig, err := server.NewTLSConfig(&cfg.TLS, []string{"h2"})
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	s := grpc.NewServer(opts...)
+	ipgeopb.RegisterIPGeoServer(s, NewServer(ipService, cfg.MaxBatchSize))
+	return s, nil
+}
+
+// ListenAndServe 按配置启动gRPC服务
+func ListenAndServe(cfg *config.GRPCConfig, ipService *service.IPService) error {
+	s, err := NewGRPCServer(cfg, ipService)
+	if err != nil {
+		return fmt.Errorf("创建gRPC服务失败: %v", err)
+	}
+	ln, err := net.Listen("tcp", cfg.ListenAddr())
+	if err != nil {
+		return err
+	}
+
+	mode := ""
+	if cfg.TLS.Enabled() {
+		mode = " (TLS)"
+	}
+	logger.Info("gRPC服务启动在 %s%s", ln.Addr(), mode)
+	return fmt.Errorf("gRPC服务停止: %v", s.Serve(ln))
+}
@@ -0,0 +1,240 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"ip-geo/internal/api/response"
+	"ip-geo/internal/grpcapi/ipgeopb"
+	"ip-geo/internal/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testMaxBatchSize 测试使用的BatchLookup上限
+const testMaxBatchSize = 3
+
+// stubIPService 不依赖数据库文件的查询服务，ASN编号为IP的最后一个字节
+type stubIPService struct{}
+
+func (stubIPService) LookupIP(ip string) (*response.IPResponse, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, service.ErrInvalidIP
+	}
+	resp := &response.IPResponse{IP: ip, Version: "IPv6"}
+	if parsed.To4() != nil {
+		resp.Version = "IPv4"
+	}
+	resp.ASN.Number = uint(parsed[len(parsed)-1])
+	resp.ASN.Name = fmt.Sprintf("AS%d", resp.ASN.Number)
+	resp.Location.Country.Code = "CN"
+	return resp, nil
+}
+
+func (s stubIPService) DatabaseStatus(name string) (*response.DatabaseStatus, error) {
+	for _, st := range s.DatabaseStatuses() {
+		if st.Name == name {
+			return st, nil
+		}
+	}
+	return nil, service.ErrUnknownDatabase
+}
+
+func (stubIPService) DatabaseStatuses() []*response.DatabaseStatus {
+	return []*response.DatabaseStatus{
+		{Name: "asn", Role: "asn", Loaded: true, DatabaseType: "GeoLite2-ASN", BuildTime: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "city", Role: "city", Loaded: false, Error: "文件不存在"},
+	}
+}
+
+// newTestClient 在内存连接上启动gRPC服务并返回客户端
+func newTestClient(t *testing.T) ipgeopb.IPGeoClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	ipgeopb.RegisterIPGeoServer(s, NewServer(stubIPService{}, testMaxBatchSize))
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return ipgeopb.NewIPGeoClient(conn)
+}
+
+func testContext(t *testing.T) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestLookup(t *testing.T) {
+	client := newTestClient(t)
+	ctx := testContext(t)
+
+	resp, err := client.Lookup(ctx, &ipgeopb.LookupRequest{Ip: "8.8.4.4"})
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if resp.GetIp() != "8.8.4.4" || resp.GetVersion() != "IPv4" || resp.GetAsn().GetNumber() != 4 ||
+		resp.GetLocation().GetCountry().GetCode() != "CN" {
+		t.Errorf("Lookup = %v", resp)
+	}
+
+	_, err = client.Lookup(ctx, &ipgeopb.LookupRequest{Ip: "not-an-ip"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Lookup(not-an-ip) error = %v, want InvalidArgument", err)
+	}
+}
+
+func TestBatchLookup(t *testing.T) {
+	client := newTestClient(t)
+	ctx := testContext(t)
+
+	stream, err := client.BatchLookup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ips := []string{"1.1.1.1", "bad", "2001:db8::2"}
+	for _, ip := range ips {
+		if err := stream.Send(&ipgeopb.LookupRequest{Ip: ip}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("BatchLookup: %v", err)
+	}
+	results := resp.GetResults()
+	if len(results) != len(ips) {
+		t.Fatalf("got %d results, want %d", len(results), len(ips))
+	}
+	for i, r := range results {
+		if r.GetIp() != ips[i] {
+			t.Errorf("results[%d].Ip = %q, want %q", i, r.GetIp(), ips[i])
+		}
+	}
+	// 单个IP查询失败记录在结果中
+	if results[1].GetError() == "" || results[1].GetResponse() != nil {
+		t.Errorf("results[1] = %v, want error", results[1])
+	}
+	if results[2].GetResponse().GetAsn().GetNumber() != 2 {
+		t.Errorf("results[2] = %v", results[2])
+	}
+}
+
+func TestBatchLookupLimit(t *testing.T) {
+	client := newTestClient(t)
+	ctx := testContext(t)
+
+	stream, err := client.BatchLookup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i <= testMaxBatchSize; i++ {
+		// 服务端拒绝后发送可能返回io.EOF，错误在CloseAndRecv中返回
+		if err := stream.Send(&ipgeopb.LookupRequest{Ip: fmt.Sprintf("10.0.0.%d", i)}); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = stream.CloseAndRecv()
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("BatchLookup error = %v, want ResourceExhausted", err)
+	}
+}
+
+func TestStreamLookup(t *testing.T) {
+	client := newTestClient(t)
+	ctx := testContext(t)
+
+	stream, err := client.StreamLookup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 数量超过BatchLookup的上限，流式查询不受限制
+	ips := []string{"10.0.0.1", "10.0.0.2", "bad", "2001:db8::4", "10.0.0.5"}
+	go func() {
+		for _, ip := range ips {
+			if err := stream.Send(&ipgeopb.LookupRequest{Ip: ip}); err != nil {
+				return
+			}
+		}
+		stream.CloseSend()
+	}()
+
+	var got []*ipgeopb.LookupResult
+	for {
+		r, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		got = append(got, r)
+	}
+	if len(got) != len(ips) {
+		t.Fatalf("got %d results, want %d", len(got), len(ips))
+	}
+	// 结果按请求顺序返回
+	for i, r := range got {
+		if r.GetIp() != ips[i] {
+			t.Errorf("results[%d].Ip = %q, want %q", i, r.GetIp(), ips[i])
+		}
+		if wantErr := ips[i] == "bad"; (r.GetError() != "") != wantErr {
+			t.Errorf("results[%d].Error = %q", i, r.GetError())
+		}
+	}
+}
+
+func TestGetDatabaseInfo(t *testing.T) {
+	client := newTestClient(t)
+	ctx := testContext(t)
+
+	resp, err := client.GetDatabaseInfo(ctx, &ipgeopb.GetDatabaseInfoRequest{})
+	if err != nil {
+		t.Fatalf("GetDatabaseInfo: %v", err)
+	}
+	if len(resp.GetDatabases()) != 2 {
+		t.Fatalf("got %d databases, want 2", len(resp.GetDatabases()))
+	}
+
+	resp, err = client.GetDatabaseInfo(ctx, &ipgeopb.GetDatabaseInfoRequest{Name: "asn"})
+	if err != nil {
+		t.Fatalf("GetDatabaseInfo(asn): %v", err)
+	}
+	if len(resp.GetDatabases()) != 1 {
+		t.Fatalf("got %d databases, want 1", len(resp.GetDatabases()))
+	}
+	info := resp.GetDatabases()[0]
+	if info.GetName() != "asn" || !info.GetLoaded() || info.GetDatabaseType() != "GeoLite2-ASN" {
+		t.Errorf("GetDatabaseInfo(asn) = %v", info)
+	}
+	if want := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC); !info.GetBuildTime().AsTime().Equal(want) {
+		t.Errorf("BuildTime = %v, want %v", info.GetBuildTime().AsTime(), want)
+	}
+
+	_, err = client.GetDatabaseInfo(ctx, &ipgeopb.GetDatabaseInfoRequest{Name: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetDatabaseInfo(missing) error = %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"ip-geo/internal/logger"
+	"ip-geo/internal/ratelimit"
+)
+
+// RateLimit 按客户端连接地址限制请求速率，超过限额时返回429
+//
+// 不使用X-Forwarded-For等请求头，避免客户端伪造地址绕过限制。
+func RateLimit(limiter *ratelimit.Limiter, next http.Handler) http.Handler {
+	if !limiter.Enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !limiter.Allow(ratelimit.ClientKey(r.RemoteAddr)) {
+			logger.Debug("请求过于频繁: %s %s", r.RemoteAddr, r.URL.Path)
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "请求过于频繁，请稍后再试", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,120 @@
+package mmdbdiff
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"net"
+)
+
+// addr 128位地址，IPv4地址位于::/96，与MMDB搜索树中的位置一致
+type addr struct {
+	hi, lo uint64
+}
+
+// maxAddr 地址空间中的最后一个地址
+var maxAddr = addr{hi: ^uint64(0), lo: ^uint64(0)}
+
+// fromIP 将IP转换为128位地址
+func fromIP(ip net.IP) addr {
+	if len(ip) == net.IPv4len {
+		return addr{lo: uint64(binary.BigEndian.Uint32(ip))}
+	}
+	ip16 := ip.To16()
+	return addr{
+		hi: binary.BigEndian.Uint64(ip16[:8]),
+		lo: binary.BigEndian.Uint64(ip16[8:]),
+	}
+}
+
+// span 返回网段的首尾地址
+func span(network *net.IPNet) (addr, addr) {
+	first := fromIP(network.IP)
+	ones, size := network.Mask.Size()
+	if size == 32 {
+		ones += 96
+	}
+	return first, first.or(hostMask(ones))
+}
+
+// hostMask 返回前缀长度对应的主机位掩码
+func hostMask(prefixLen int) addr {
+	switch {
+	case prefixLen <= 0:
+		return maxAddr
+	case prefixLen < 64:
+		return addr{hi: ^uint64(0) >> prefixLen, lo: ^uint64(0)}
+	case prefixLen < 128:
+		return addr{lo: ^uint64(0) >> (prefixLen - 64)}
+	}
+	return addr{}
+}
+
+// less 判断a是否小于b
+func (a addr) less(b addr) bool {
+	return a.hi < b.hi || a.hi == b.hi && a.lo < b.lo
+}
+
+// or 按位或
+func (a addr) or(b addr) addr {
+	return addr{hi: a.hi | b.hi, lo: a.lo | b.lo}
+}
+
+// next 返回下一个地址，调用方需保证a不是最后一个地址
+func (a addr) next() addr {
+	lo, carry := bits.Add64(a.lo, 1, 0)
+	return addr{hi: a.hi + carry, lo: lo}
+}
+
+// prev 返回上一个地址，调用方需保证a不是第一个地址
+func (a addr) prev() addr {
+	lo, borrow := bits.Sub64(a.lo, 1, 0)
+	return addr{hi: a.hi - borrow, lo: lo}
+}
+
+// trailingZeros 返回地址末尾0的位数
+func (a addr) trailingZeros() int {
+	if a.lo != 0 {
+		return bits.TrailingZeros64(a.lo)
+	}
+	if a.hi != 0 {
+		return 64 + bits.TrailingZeros64(a.hi)
+	}
+	return 128
+}
+
+// isIPv4 判断地址是否位于IPv4子树
+func (a addr) isIPv4() bool {
+	return a.hi == 0 && a.lo>>32 == 0
+}
+
+// toIPNet 将地址和前缀长度转换为网段，IPv4子树中的网段转换为IPv4形式
+func (a addr) toIPNet(prefixLen int) *net.IPNet {
+	if a.isIPv4() && prefixLen >= 96 {
+		ip := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(ip, uint32(a.lo))
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(prefixLen-96, 32)}
+	}
+	ip := make(net.IP, net.IPv6len)
+	binary.BigEndian.PutUint64(ip[:8], a.hi)
+	binary.BigEndian.PutUint64(ip[8:], a.lo)
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(prefixLen, 128)}
+}
+
+// rangeToNetworks 将地址范围拆分为最少数量的CIDR网段
+func rangeToNetworks(first, last addr) []*net.IPNet {
+	var networks []*net.IPNet
+	for {
+		// 从first开始能容纳的最大对齐网段，且不超过last
+		prefixLen := 128 - first.trailingZeros()
+		for prefixLen < 128 && last.less(first.or(hostMask(prefixLen))) {
+			prefixLen++
+		}
+		networks = append(networks, first.toIPNet(prefixLen))
+
+		end := first.or(hostMask(prefixLen))
+		if end == last || end == maxAddr {
+			return networks
+		}
+		first = end.next()
+	}
+}
@@ -0,0 +1,386 @@
+// Package mmdbdiff 比较同一MMDB数据库的两个版本
+//
+// 同时按地址顺序遍历两个数据库的搜索树，将两边的网段边界合并后逐段比较国家、ASN和地区，
+// 报告新增、删除和重新分配的网段。
+package mmdbdiff
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"time"
+
+	"ip-geo/internal/provider"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// 网段变化类型
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// 参与比较的字段
+const (
+	FieldCountry = "country"
+	FieldASN     = "asn"
+	FieldRegion  = "region"
+)
+
+// Key 参与比较的记录属性
+type Key struct {
+	Country    string `json:"country,omitempty"`
+	ASN        uint   `json:"asn,omitempty"`
+	RegionCode string `json:"region_code,omitempty"`
+	RegionName string `json:"region_name,omitempty"`
+}
+
+// Change 单个网段的变化
+type Change struct {
+	Network *net.IPNet
+	Kind    string
+	// Fields 发生变化的字段，仅用于ChangeChanged
+	Fields []string
+	// Old/New 变化前后的属性，新增时Old为nil，删除时New为nil
+	Old *Key
+	New *Key
+}
+
+// Counts 变化统计
+type Counts struct {
+	Added          int `json:"added"`
+	Removed        int `json:"removed"`
+	Changed        int `json:"changed"`
+	CountryChanged int `json:"country_changed"`
+	ASNChanged     int `json:"asn_changed"`
+	RegionChanged  int `json:"region_changed"`
+}
+
+// DatabaseInfo 参与比较的数据库信息
+type DatabaseInfo struct {
+	DatabaseType string    `json:"database_type"`
+	BuildTime    time.Time `json:"build_time"`
+}
+
+// Report 比较结果汇总
+//
+// 按国家统计时新增和变化的网段计入新国家，删除的网段计入原国家，按ASN统计同理。
+type Report struct {
+	Old       DatabaseInfo       `json:"old"`
+	New       DatabaseInfo       `json:"new"`
+	Schema    string             `json:"schema"`
+	Total     Counts             `json:"total"`
+	ByCountry map[string]*Counts `json:"by_country"`
+	ByASN     map[uint]*Counts   `json:"by_asn"`
+}
+
+// Options 比较选项
+type Options struct {
+	// Schema 记录格式，为空时根据旧数据库的类型自动识别
+	Schema string
+	// OnChange 每个发生变化的网段都会调用，返回错误时终止比较
+	OnChange func(*Change) error
+}
+
+// Diff 比较同一数据库的两个版本
+func Diff(oldReader, newReader *maxminddb.Reader, opts Options) (*Report, error) {
+	schema := opts.Schema
+	if schema == "" {
+		detected, ok := provider.DetectSchema(oldReader.Metadata.DatabaseType)
+		if !ok {
+			return nil, fmt.Errorf("无法识别数据库类型: %s", oldReader.Metadata.DatabaseType)
+		}
+		schema = detected
+	}
+
+	oldProvider, err := provider.New("old", oldReader, schema)
+	if err != nil {
+		return nil, err
+	}
+	newProvider, err := provider.New("new", newReader, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &differ{
+		report: &Report{
+			Old:       databaseInfo(oldReader),
+			New:       databaseInfo(newReader),
+			Schema:    schema,
+			ByCountry: make(map[string]*Counts),
+			ByASN:     make(map[uint]*Counts),
+		},
+		onChange: opts.OnChange,
+	}
+
+	if err := d.sweep(newCursor(oldReader, oldProvider), newCursor(newReader, newProvider)); err != nil {
+		return nil, err
+	}
+	return d.report, nil
+}
+
+// databaseInfo 读取数据库元数据
+func databaseInfo(reader *maxminddb.Reader) DatabaseInfo {
+	return DatabaseInfo{
+		DatabaseType: reader.Metadata.DatabaseType,
+		BuildTime:    time.Unix(int64(reader.Metadata.BuildEpoch), 0).UTC(),
+	}
+}
+
+// cursor 按地址顺序遍历数据库中的网段
+type cursor struct {
+	networks *maxminddb.Networks
+	provider provider.Provider
+
+	first, last addr
+	key         *Key
+	done        bool
+}
+
+// newCursor 创建遍历器，需调用advance读取第一个网段
+func newCursor(reader *maxminddb.Reader, p provider.Provider) *cursor {
+	return &cursor{
+		networks: reader.Networks(maxminddb.SkipAliasedNetworks),
+		provider: p,
+	}
+}
+
+// advance 读取下一个有记录的网段
+func (c *cursor) advance() error {
+	for c.networks.Next() {
+		var skip struct{}
+		network, err := c.networks.Network(&skip)
+		if err != nil {
+			return err
+		}
+
+		record, err := c.provider.Lookup(network.IP)
+		if err != nil {
+			return err
+		}
+		if record == nil {
+			// 数据源认为无效的记录，如没有省份和ISP的GeoCN记录
+			continue
+		}
+
+		c.first, c.last = span(network)
+		c.key = &Key{
+			Country:    record.CountryCode,
+			ASN:        record.ASNNumber,
+			RegionCode: record.RegionCode,
+			RegionName: record.RegionName,
+		}
+		return nil
+	}
+	c.done = true
+	return c.networks.Err()
+}
+
+// covers 判断当前网段是否包含该地址
+func (c *cursor) covers(a addr) bool {
+	return !c.done && !a.less(c.first)
+}
+
+// pending 尚未输出的连续变化范围
+type pending struct {
+	first, last addr
+	kind        string
+	old, new    *Key
+}
+
+// differ 保存比较过程中的状态
+type differ struct {
+	report   *Report
+	onChange func(*Change) error
+	pending  *pending
+}
+
+// sweep 合并两个数据库的网段边界，逐段比较
+func (d *differ) sweep(a, b *cursor) error {
+	if err := a.advance(); err != nil {
+		return err
+	}
+	if err := b.advance(); err != nil {
+		return err
+	}
+
+	pos := addr{}
+	for !a.done || !b.done {
+		// 两边都没有覆盖当前位置时跳到下一个网段的起点
+		if !a.covers(pos) && !b.covers(pos) {
+			switch {
+			case a.done:
+				pos = b.first
+			case b.done:
+				pos = a.first
+			case a.first.less(b.first):
+				pos = a.first
+			default:
+				pos = b.first
+			}
+		}
+
+		// 本段的终点为覆盖网段的终点或下一个网段起点的前一个地址
+		end := maxAddr
+		var oldKey, newKey *Key
+		for _, c := range []*cursor{a, b} {
+			if c.done {
+				continue
+			}
+			limit := c.last
+			if !c.covers(pos) {
+				limit = c.first.prev()
+			}
+			if limit.less(end) {
+				end = limit
+			}
+		}
+		if a.covers(pos) {
+			oldKey = a.key
+		}
+		if b.covers(pos) {
+			newKey = b.key
+		}
+
+		if err := d.compare(pos, end, oldKey, newKey); err != nil {
+			return err
+		}
+		if end == maxAddr {
+			break
+		}
+		pos = end.next()
+
+		for _, c := range []*cursor{a, b} {
+			if !c.done && c.last.less(pos) {
+				if err := c.advance(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return d.flush()
+}
+
+// compare 比较一段地址范围，相邻且变化相同的范围合并后再输出
+func (d *differ) compare(first, last addr, oldKey, newKey *Key) error {
+	var kind string
+	switch {
+	case oldKey == nil && newKey == nil:
+		return d.flush()
+	case oldKey == nil:
+		kind = ChangeAdded
+	case newKey == nil:
+		kind = ChangeRemoved
+	case len(changedFields(oldKey, newKey)) > 0:
+		kind = ChangeChanged
+	default:
+		return d.flush()
+	}
+
+	if p := d.pending; p != nil && p.last.next() == first && p.kind == kind &&
+		equalKey(p.old, oldKey) && equalKey(p.new, newKey) {
+		p.last = last
+		return nil
+	}
+
+	if err := d.flush(); err != nil {
+		return err
+	}
+	d.pending = &pending{first: first, last: last, kind: kind, old: oldKey, new: newKey}
+	return nil
+}
+
+// flush 将待输出的范围拆分为CIDR网段并输出
+func (d *differ) flush() error {
+	p := d.pending
+	if p == nil {
+		return nil
+	}
+	d.pending = nil
+
+	var fields []string
+	if p.kind == ChangeChanged {
+		fields = changedFields(p.old, p.new)
+	}
+
+	for _, network := range rangeToNetworks(p.first, p.last) {
+		change := &Change{
+			Network: network,
+			Kind:    p.kind,
+			Fields:  fields,
+			Old:     p.old,
+			New:     p.new,
+		}
+		d.count(change)
+		if d.onChange != nil {
+			if err := d.onChange(change); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// count 更新统计信息
+func (d *differ) count(change *Change) {
+	key := change.New
+	if change.Kind == ChangeRemoved {
+		key = change.Old
+	}
+
+	byCountry, ok := d.report.ByCountry[key.Country]
+	if !ok {
+		byCountry = &Counts{}
+		d.report.ByCountry[key.Country] = byCountry
+	}
+	byASN, ok := d.report.ByASN[key.ASN]
+	if !ok {
+		byASN = &Counts{}
+		d.report.ByASN[key.ASN] = byASN
+	}
+
+	for _, counts := range []*Counts{&d.report.Total, byCountry, byASN} {
+		switch change.Kind {
+		case ChangeAdded:
+			counts.Added++
+		case ChangeRemoved:
+			counts.Removed++
+		case ChangeChanged:
+			counts.Changed++
+			if slices.Contains(change.Fields, FieldCountry) {
+				counts.CountryChanged++
+			}
+			if slices.Contains(change.Fields, FieldASN) {
+				counts.ASNChanged++
+			}
+			if slices.Contains(change.Fields, FieldRegion) {
+				counts.RegionChanged++
+			}
+		}
+	}
+}
+
+// changedFields 返回两条记录中发生变化的字段
+func changedFields(oldKey, newKey *Key) []string {
+	var fields []string
+	if oldKey.Country != newKey.Country {
+		fields = append(fields, FieldCountry)
+	}
+	if oldKey.ASN != newKey.ASN {
+		fields = append(fields, FieldASN)
+	}
+	if oldKey.RegionCode != newKey.RegionCode || oldKey.RegionName != newKey.RegionName {
+		fields = append(fields, FieldRegion)
+	}
+	return fields
+}
+
+// equalKey 比较两个可能为nil的属性
+func equalKey(a, b *Key) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
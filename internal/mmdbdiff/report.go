@@ -0,0 +1,149 @@
+package mmdbdiff
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// csvHeader 完整差异CSV的表头
+var csvHeader = []string{
+	"network", "change", "fields",
+	"old_country", "new_country",
+	"old_asn", "new_asn",
+	"old_region_code", "new_region_code",
+	"old_region", "new_region",
+}
+
+// CSVWriter 将网段变化写为CSV
+type CSVWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+// NewCSVWriter 创建新的CSVWriter实例
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+// Write 写入一个网段的变化，可直接作为Options.OnChange使用
+func (cw *CSVWriter) Write(change *Change) error {
+	if !cw.wroteHeader {
+		if err := cw.w.Write(csvHeader); err != nil {
+			return err
+		}
+		cw.wroteHeader = true
+	}
+
+	var oldKey, newKey Key
+	if change.Old != nil {
+		oldKey = *change.Old
+	}
+	if change.New != nil {
+		newKey = *change.New
+	}
+
+	return cw.w.Write([]string{
+		change.Network.String(),
+		change.Kind,
+		strings.Join(change.Fields, "|"),
+		oldKey.Country, newKey.Country,
+		formatASN(oldKey.ASN), formatASN(newKey.ASN),
+		oldKey.RegionCode, newKey.RegionCode,
+		oldKey.RegionName, newKey.RegionName,
+	})
+}
+
+// Flush 刷新缓冲区，没有任何变化时也会写入表头
+func (cw *CSVWriter) Flush() error {
+	if !cw.wroteHeader {
+		if err := cw.w.Write(csvHeader); err != nil {
+			return err
+		}
+		cw.wroteHeader = true
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// formatASN 格式化ASN，0表示没有ASN
+func formatASN(number uint) string {
+	if number == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(number), 10)
+}
+
+// total 变化的网段总数
+func (c *Counts) total() int {
+	return c.Added + c.Removed + c.Changed
+}
+
+// String 实现fmt.Stringer接口
+func (c *Counts) String() string {
+	return fmt.Sprintf("新增: %d, 删除: %d, 变化: %d (国家: %d, ASN: %d, 地区: %d)",
+		c.Added, c.Removed, c.Changed, c.CountryChanged, c.ASNChanged, c.RegionChanged)
+}
+
+// WriteSummary 输出汇总信息，按国家和ASN列出变化最多的top项
+func (r *Report) WriteSummary(w io.Writer, top int) {
+	fmt.Fprintf(w, "旧版本: %s (%s)\n", r.Old.DatabaseType, r.Old.BuildTime.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "新版本: %s (%s)\n", r.New.DatabaseType, r.New.BuildTime.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "记录格式: %s\n", r.Schema)
+	fmt.Fprintf(w, "合计 %s\n", &r.Total)
+
+	countries := make([]string, 0, len(r.ByCountry))
+	for country := range r.ByCountry {
+		countries = append(countries, country)
+	}
+	sortByTotal(countries, func(country string) *Counts { return r.ByCountry[country] })
+	// 数据库不包含国家信息时所有变化都计入空国家，不输出该部分
+	if len(countries) > 1 || len(countries) == 1 && countries[0] != "" {
+		fmt.Fprintln(w, "\n按国家:")
+		for _, country := range limit(countries, top) {
+			name := country
+			if name == "" {
+				name = "-"
+			}
+			fmt.Fprintf(w, "  %-4s %s\n", name, r.ByCountry[country])
+		}
+	}
+
+	asns := make([]uint, 0, len(r.ByASN))
+	for number := range r.ByASN {
+		asns = append(asns, number)
+	}
+	sortByTotal(asns, func(number uint) *Counts { return r.ByASN[number] })
+	if len(asns) > 1 || len(asns) == 1 && asns[0] != 0 {
+		fmt.Fprintln(w, "\n按ASN:")
+		for _, number := range limit(asns, top) {
+			name := "-"
+			if number != 0 {
+				name = "AS" + strconv.FormatUint(uint64(number), 10)
+			}
+			fmt.Fprintf(w, "  %-10s %s\n", name, r.ByASN[number])
+		}
+	}
+}
+
+// sortByTotal 按变化总数从多到少排序，总数相同时保持键的顺序稳定
+func sortByTotal[K string | uint](keys []K, counts func(K) *Counts) {
+	sort.Slice(keys, func(i, j int) bool {
+		ti, tj := counts(keys[i]).total(), counts(keys[j]).total()
+		if ti != tj {
+			return ti > tj
+		}
+		return keys[i] < keys[j]
+	})
+}
+
+// limit 截取前n项，n<=0时不限制
+func limit[K any](keys []K, n int) []K {
+	if n > 0 && len(keys) > n {
+		return keys[:n]
+	}
+	return keys
+}
@@ -0,0 +1,135 @@
+package mmdbmerge
+
+import (
+	"fmt"
+	"net"
+
+	"ip-geo/internal/mmdbwriter"
+	"ip-geo/internal/provider"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// CountryDatabaseType 国家级数据库的类型，可被provider.DetectSchema识别为GeoIP2 City格式
+const CountryDatabaseType = "IPGeo-Fallback-Country"
+
+// CountryLanguages 国家级数据库保留的语言，与provider中名称的取值顺序一致
+var CountryLanguages = []string{"zh-CN", "en"}
+
+// ReduceToCountry 从GeoIP2 City数据库中提取洲和国家信息，生成体积较小的国家级数据库
+//
+// 只保留CountryLanguages中的名称，相邻的相同记录在写入时合并，返回写入的网段数。
+func ReduceToCountry(city *maxminddb.Reader) (*mmdbwriter.Tree, int, error) {
+	tree := mmdbwriter.New(mmdbwriter.Metadata{
+		DatabaseType: CountryDatabaseType,
+		Description: map[string]string{
+			"en": fmt.Sprintf("Country level data from %s", city.Metadata.DatabaseType),
+		},
+		Languages:  CountryLanguages,
+		IPVersion:  int(city.Metadata.IPVersion),
+		BuildEpoch: int64(city.Metadata.BuildEpoch),
+	})
+
+	count := 0
+	err := eachNetwork(city, func(network *net.IPNet, value any) error {
+		record, _ := value.(map[string]any)
+		reduced := make(map[string]any)
+		for _, key := range []string{"continent", "country", "registered_country"} {
+			if v := reducePlace(record[key]); v != nil {
+				reduced[key] = v
+			}
+		}
+		if len(reduced) == 0 {
+			return nil
+		}
+		if traits, ok := record["traits"].(map[string]any); ok && traits["is_anycast"] == true {
+			reduced["traits"] = map[string]any{"is_anycast": true}
+		}
+		count++
+		return tree.Insert(network, reduced)
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("读取City数据库失败: %v", err)
+	}
+	return tree, count, nil
+}
+
+// reducePlace 保留洲或国家记录中的代码和指定语言的名称
+func reducePlace(value any) map[string]any {
+	place, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	reduced := make(map[string]any)
+	for _, key := range []string{"code", "iso_code"} {
+		if code, ok := place[key].(string); ok && code != "" {
+			reduced[key] = code
+		}
+	}
+	if names, ok := place["names"].(map[string]any); ok {
+		kept := make(map[string]any)
+		for _, lang := range CountryLanguages {
+			if name, ok := names[lang].(string); ok && name != "" {
+				kept[lang] = name
+			}
+		}
+		if len(kept) > 0 {
+			reduced["names"] = kept
+		}
+	}
+	if len(reduced) == 0 {
+		return nil
+	}
+	return reduced
+}
+
+// VerifyCountry 重新打开国家级数据库，校验文件结构，
+// 并检查City数据库中每个网段的洲和国家与国家级数据库的查询结果一致
+func VerifyCountry(path string, city *maxminddb.Reader) error {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开数据库失败: %v", err)
+	}
+	defer reader.Close()
+
+	if err := reader.Verify(); err != nil {
+		return fmt.Errorf("数据库结构校验失败: %v", err)
+	}
+
+	country, err := provider.New(provider.NameFallback, reader, provider.SchemaGeoIP2City)
+	if err != nil {
+		return err
+	}
+	cityProvider, err := provider.New(provider.NameCity, city, provider.SchemaGeoIP2City)
+	if err != nil {
+		return err
+	}
+
+	return eachNetwork(city, func(network *net.IPNet, _ any) error {
+		ip := network.IP
+		if ip.To4() == nil && reader.Metadata.IPVersion == 4 {
+			return nil
+		}
+		expected, err := cityProvider.Lookup(ip)
+		if err != nil {
+			return err
+		}
+		actual, err := country.Lookup(ip)
+		if err != nil {
+			return err
+		}
+		if countryKey(expected) != countryKey(actual) {
+			return fmt.Errorf("%s 的查询结果不一致: 期望 %s，实际 %s", ip, countryKey(expected), countryKey(actual))
+		}
+		return nil
+	})
+}
+
+// countryKey 返回记录中的洲和国家，用于比较
+func countryKey(r *provider.Record) string {
+	if r == nil || (r.ContinentCode == "" && r.CountryCode == "" && r.ContinentName == "" && r.CountryName == "") {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s %s/%s", r.ContinentCode, r.ContinentName, r.CountryCode, r.CountryName)
+}
@@ -0,0 +1,195 @@
+// Package mmdbmerge 离线合并MMDB数据库
+//
+// 将GeoCN的省市区和ISP数据合并到GeoIP2 City数据库中，查询时只需遍历一次搜索树，
+// 合并结果可复现，便于比较不同版本之间的差异。
+package mmdbmerge
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+
+	"ip-geo/internal/mmdbwriter"
+	"ip-geo/internal/provider"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// DatabaseType 合并后的数据库类型，可被provider.DetectSchema识别
+const DatabaseType = "GeoIP2-City-GeoCN"
+
+// Stats 合并统计信息
+type Stats struct {
+	CityNetworks  int
+	GeoCNNetworks int
+	// Skipped 没有省份和ISP信息或无法写入的GeoCN网段数
+	Skipped int
+}
+
+// String 实现fmt.Stringer接口
+func (s *Stats) String() string {
+	return fmt.Sprintf("City网段: %d, GeoCN网段: %d, 跳过: %d", s.CityNetworks, s.GeoCNNetworks, s.Skipped)
+}
+
+// MergeGeoCN 将GeoCN记录合并到GeoIP2 City搜索树中
+//
+// 合并后的记录顶层保持GeoIP2 City格式，GeoCN原始记录写入provider.GeoCNKey键下。
+// 构建时间取两个数据库中较新的一个，相同输入总是生成相同的文件。
+func MergeGeoCN(city, geoCN *maxminddb.Reader) (*mmdbwriter.Tree, *Stats, error) {
+	buildEpoch := city.Metadata.BuildEpoch
+	if geoCN.Metadata.BuildEpoch > buildEpoch {
+		buildEpoch = geoCN.Metadata.BuildEpoch
+	}
+
+	tree := mmdbwriter.New(mmdbwriter.Metadata{
+		DatabaseType: DatabaseType,
+		Description: map[string]string{
+			"en": fmt.Sprintf("%s merged with %s", city.Metadata.DatabaseType, geoCN.Metadata.DatabaseType),
+		},
+		Languages:  city.Metadata.Languages,
+		IPVersion:  int(city.Metadata.IPVersion),
+		BuildEpoch: int64(buildEpoch),
+	})
+	stats := &Stats{}
+
+	err := eachNetwork(city, func(network *net.IPNet, value any) error {
+		stats.CityNetworks++
+		return tree.Insert(network, value)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取City数据库失败: %v", err)
+	}
+
+	err = eachNetwork(geoCN, func(network *net.IPNet, value any) error {
+		stats.GeoCNNetworks++
+
+		// 与provider中GeoCN记录的有效性判断保持一致
+		record, _ := value.(map[string]any)
+		province, _ := record["province"].(string)
+		isp, _ := record["isp"].(string)
+		if province == "" && isp == "" {
+			stats.Skipped++
+			return nil
+		}
+
+		err := tree.InsertFunc(network, func(existing any) any {
+			merged := make(map[string]any)
+			if existingRecord, ok := existing.(map[string]any); ok {
+				for key, v := range existingRecord {
+					merged[key] = v
+				}
+			}
+			merged[provider.GeoCNKey] = value
+			return merged
+		})
+		if err != nil {
+			stats.Skipped++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取GeoCN数据库失败: %v", err)
+	}
+
+	return tree, stats, nil
+}
+
+// VerifyGeoCN 重新打开合并后的数据库，校验文件结构，
+// 并检查两个源数据库中每个网段的查询结果与分别查询后合并的结果一致
+func VerifyGeoCN(path string, city, geoCN *maxminddb.Reader) error {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开数据库失败: %v", err)
+	}
+	defer reader.Close()
+
+	if err := reader.Verify(); err != nil {
+		return fmt.Errorf("数据库结构校验失败: %v", err)
+	}
+
+	merged, err := provider.New(provider.NameCity, reader, provider.SchemaGeoIP2CityGeoCN)
+	if err != nil {
+		return err
+	}
+	cityProvider, err := provider.New(provider.NameCity, city, provider.SchemaGeoIP2City)
+	if err != nil {
+		return err
+	}
+	geoCNProvider, err := provider.New(provider.NameGeoCN, geoCN, provider.SchemaGeoCN)
+	if err != nil {
+		return err
+	}
+
+	check := func(network *net.IPNet, _ any) error {
+		ip := network.IP
+		if ip.To4() == nil && reader.Metadata.IPVersion == 4 {
+			// IPv4数据库无法写入IPv6网段
+			return nil
+		}
+		cityRecord, err := cityProvider.Lookup(ip)
+		if err != nil {
+			return err
+		}
+		geoCNRecord, err := geoCNProvider.Lookup(ip)
+		if err != nil {
+			return err
+		}
+		actual, err := merged.Lookup(ip)
+		if err != nil {
+			return err
+		}
+
+		expected := provider.MergeGeoCN(cityRecord, geoCNRecord)
+		if !equalRecord(expected, actual) {
+			return fmt.Errorf("%s 的查询结果不一致: 期望 %+v，实际 %+v", ip, expected, actual)
+		}
+		return nil
+	}
+
+	if err := eachNetwork(city, check); err != nil {
+		return err
+	}
+	return eachNetwork(geoCN, check)
+}
+
+// eachNetwork 按地址顺序遍历数据库中的网段，相同的记录只解码一次
+func eachNetwork(reader *maxminddb.Reader, f func(network *net.IPNet, value any) error) error {
+	values := make(map[uintptr]any)
+	networks := reader.Networks(maxminddb.SkipAliasedNetworks)
+	for networks.Next() {
+		var skip struct{}
+		network, err := networks.Network(&skip)
+		if err != nil {
+			return err
+		}
+
+		offset, err := reader.LookupOffset(network.IP)
+		if err != nil {
+			return err
+		}
+		value, ok := values[offset]
+		if !ok {
+			if err := reader.Decode(offset, &value); err != nil {
+				return err
+			}
+			values[offset] = value
+		}
+
+		if err := f(network, value); err != nil {
+			return err
+		}
+	}
+	return networks.Err()
+}
+
+// equalRecord 比较两条记录，忽略网段
+//
+// 合并后相邻的相同记录可能被合并为更大的网段，因此网段不一定与源数据库相同。
+func equalRecord(a, b *provider.Record) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	x, y := *a, *b
+	x.Network, y.Network = nil, nil
+	return reflect.DeepEqual(x, y)
+}
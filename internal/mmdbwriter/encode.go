@@ -0,0 +1,178 @@
+package mmdbwriter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+)
+
+// 数据段类型编号
+const (
+	typeString  = 2
+	typeDouble  = 3
+	typeBytes   = 4
+	typeUint16  = 5
+	typeUint32  = 6
+	typeMap     = 7
+	typeInt32   = 8
+	typeUint64  = 9
+	typeUint128 = 10
+	typeArray   = 11
+	typeBool    = 14
+	typeFloat   = 15
+)
+
+// encode 将值编码为数据段格式
+//
+// 支持的类型与maxminddb-golang解码到any时产生的类型一致，
+// 因此从现有数据库读出的记录可以原样写回。
+func encode(buf *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case string:
+		writeControl(buf, typeString, len(v))
+		buf.WriteString(v)
+	case []byte:
+		writeControl(buf, typeBytes, len(v))
+		buf.Write(v)
+	case bool:
+		size := 0
+		if v {
+			size = 1
+		}
+		writeControl(buf, typeBool, size)
+	case float64:
+		writeControl(buf, typeDouble, 8)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(v))
+	case float32:
+		writeControl(buf, typeFloat, 4)
+		binary.Write(buf, binary.BigEndian, math.Float32bits(v))
+	case uint16:
+		writeUint(buf, typeUint16, uint64(v))
+	case uint32:
+		writeUint(buf, typeUint32, uint64(v))
+	case uint64:
+		writeUint(buf, typeUint64, v)
+	case uint:
+		writeUint(buf, typeUint64, uint64(v))
+	case int32:
+		writeInt32(buf, v)
+	case int:
+		switch {
+		case v >= math.MinInt32 && v <= math.MaxInt32:
+			writeInt32(buf, int32(v))
+		case v > 0:
+			writeUint(buf, typeUint64, uint64(v))
+		default:
+			return fmt.Errorf("整数超出范围: %d", v)
+		}
+	case *big.Int:
+		if v.Sign() < 0 || v.BitLen() > 128 {
+			return fmt.Errorf("uint128超出范围: %s", v)
+		}
+		b := v.Bytes()
+		writeControl(buf, typeUint128, len(b))
+		buf.Write(b)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		writeControl(buf, typeMap, len(v))
+		for _, key := range keys {
+			if err := encode(buf, key); err != nil {
+				return err
+			}
+			if err := encode(buf, v[key]); err != nil {
+				return fmt.Errorf("编码字段 %s 失败: %v", key, err)
+			}
+		}
+	case map[string]string:
+		m := make(map[string]any, len(v))
+		for key, val := range v {
+			m[key] = val
+		}
+		return encode(buf, m)
+	case []any:
+		writeControl(buf, typeArray, len(v))
+		for _, item := range v {
+			if err := encode(buf, item); err != nil {
+				return err
+			}
+		}
+	case []string:
+		writeControl(buf, typeArray, len(v))
+		for _, item := range v {
+			if err := encode(buf, item); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("不支持的数据类型: %T", value)
+	}
+	return nil
+}
+
+// writeControl 写入控制字节、扩展类型字节和长度扩展字节
+func writeControl(buf *bytes.Buffer, typeNum, size int) {
+	var control byte
+	if typeNum <= 7 {
+		control = byte(typeNum << 5)
+	}
+
+	var sizeBytes []byte
+	switch {
+	case size < 29:
+		control |= byte(size)
+	case size < 29+256:
+		control |= 29
+		sizeBytes = []byte{byte(size - 29)}
+	case size < 285+65536:
+		control |= 30
+		size -= 285
+		sizeBytes = []byte{byte(size >> 8), byte(size)}
+	default:
+		control |= 31
+		size -= 65821
+		sizeBytes = []byte{byte(size >> 16), byte(size >> 8), byte(size)}
+	}
+
+	buf.WriteByte(control)
+	if typeNum > 7 {
+		buf.WriteByte(byte(typeNum - 7))
+	}
+	buf.Write(sizeBytes)
+}
+
+// writeUint 以最少字节数写入无符号整数
+func writeUint(buf *bytes.Buffer, typeNum int, v uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	i := 0
+	for i < len(b) && b[i] == 0 {
+		i++
+	}
+	writeControl(buf, typeNum, len(b)-i)
+	buf.Write(b[i:])
+}
+
+// writeInt32 写入有符号整数，负数固定使用4字节
+func writeInt32(buf *bytes.Buffer, v int32) {
+	if v < 0 {
+		writeControl(buf, typeInt32, 4)
+		binary.Write(buf, binary.BigEndian, v)
+		return
+	}
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(v))
+	i := 0
+	for i < len(b) && b[i] == 0 {
+		i++
+	}
+	writeControl(buf, typeInt32, len(b)-i)
+	buf.Write(b[i:])
+}
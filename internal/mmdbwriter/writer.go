@@ -0,0 +1,405 @@
+// Package mmdbwriter 实现MaxMind DB格式文件的写入
+package mmdbwriter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+)
+
+// dataSectionSeparatorSize 搜索树与数据段之间的分隔字节数
+const dataSectionSeparatorSize = 16
+
+// metadataStartMarker 元数据起始标记
+var metadataStartMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// Metadata 写入文件的数据库元数据
+type Metadata struct {
+	DatabaseType string
+	Description  map[string]string
+	Languages    []string
+	// IPVersion 为4或6，默认为6
+	IPVersion int
+	// BuildEpoch 为0时使用写入时间
+	BuildEpoch int64
+}
+
+// Tree 表示待写入的MaxMind DB搜索树
+type Tree struct {
+	Metadata Metadata
+	root     *node
+}
+
+// node 搜索树节点，没有子节点时为叶子节点
+type node struct {
+	children [2]*node
+	value    any
+}
+
+// New 创建新的搜索树
+func New(metadata Metadata) *Tree {
+	if metadata.IPVersion == 0 {
+		metadata.IPVersion = 6
+	}
+	return &Tree{
+		Metadata: metadata,
+		root:     &node{},
+	}
+}
+
+// Insert 插入网段，覆盖该网段内已有的所有数据
+func (t *Tree) Insert(network *net.IPNet, value any) error {
+	n, err := t.descend(network)
+	if err != nil {
+		return err
+	}
+	n.children = [2]*node{}
+	n.value = value
+	return nil
+}
+
+// InsertFunc 插入网段，对该网段内的每个已有记录调用f计算新值
+//
+// 没有数据的部分以nil调用f，f返回nil表示该部分不包含数据。
+func (t *Tree) InsertFunc(network *net.IPNet, f func(existing any) any) error {
+	n, err := t.descend(network)
+	if err != nil {
+		return err
+	}
+	n.apply(f)
+	return nil
+}
+
+// descend 定位到网段对应的节点，途经的叶子节点会被拆分
+func (t *Tree) descend(network *net.IPNet) (*node, error) {
+	ip, prefixLen, err := t.key(network)
+	if err != nil {
+		return nil, err
+	}
+
+	n := t.root
+	for i := 0; i < prefixLen; i++ {
+		if n.isLeaf() {
+			n.split()
+		}
+		n = n.children[bitAt(ip, i)]
+	}
+	return n, nil
+}
+
+// key 将网段转换为搜索树中的地址和前缀长度
+func (t *Tree) key(network *net.IPNet) (net.IP, int, error) {
+	if network == nil {
+		return nil, 0, fmt.Errorf("网段不能为空")
+	}
+	ones, bits := network.Mask.Size()
+	if bits == 0 {
+		return nil, 0, fmt.Errorf("无效的网段掩码: %s", network)
+	}
+
+	if ip4 := network.IP.To4(); ip4 != nil && bits == 32 {
+		if t.Metadata.IPVersion == 4 {
+			return ip4, ones, nil
+		}
+		// IPv6数据库中IPv4地址位于::/96子树
+		ip := make(net.IP, net.IPv6len)
+		copy(ip[12:], ip4)
+		return ip, ones + 96, nil
+	}
+
+	if t.Metadata.IPVersion == 4 {
+		return nil, 0, fmt.Errorf("IPv4数据库不能插入IPv6网段: %s", network)
+	}
+	ip := network.IP.To16()
+	if ip == nil {
+		return nil, 0, fmt.Errorf("无效的网段地址: %s", network)
+	}
+	return ip, ones, nil
+}
+
+// isLeaf 判断是否为叶子节点
+func (n *node) isLeaf() bool {
+	return n.children[0] == nil
+}
+
+// split 将叶子节点拆分为两个携带相同数据的子节点
+func (n *node) split() {
+	n.children[0] = &node{value: n.value}
+	n.children[1] = &node{value: n.value}
+	n.value = nil
+}
+
+// apply 对子树中的每个叶子节点应用f
+func (n *node) apply(f func(existing any) any) {
+	if n.isLeaf() {
+		n.value = f(n.value)
+		return
+	}
+	n.children[0].apply(f)
+	n.children[1].apply(f)
+}
+
+// WriteFile 将搜索树写入文件，先写临时文件再重命名
+func (t *Tree) WriteFile(filename string) error {
+	tmpFile := filename + ".tmp"
+	f, err := os.Create(tmpFile)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	if _, err := t.WriteTo(w); err != nil {
+		f.Close()
+		os.Remove(tmpFile)
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		os.Remove(tmpFile)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpFile)
+		return err
+	}
+
+	if err := os.Rename(tmpFile, filename); err != nil {
+		os.Remove(tmpFile)
+		return err
+	}
+	return nil
+}
+
+// WriteTo 将搜索树序列化为MaxMind DB格式
+func (t *Tree) WriteTo(w io.Writer) (int64, error) {
+	// 根节点必须是内部节点
+	if t.root.isLeaf() {
+		t.root.split()
+	}
+
+	// 编码叶子节点数据，并合并数据相同的兄弟叶子节点
+	keys := make(map[*node]string)
+	if err := encodeLeaves(t.root, keys); err != nil {
+		return 0, err
+	}
+	collapse(t.root, keys)
+	if t.root.isLeaf() {
+		key, ok := keys[t.root]
+		t.root.split()
+		if ok {
+			keys[t.root.children[0]] = key
+			keys[t.root.children[1]] = key
+		}
+	}
+
+	// 生成数据段，内容相同的记录只写入一次
+	data := &bytes.Buffer{}
+	offsets := make(map[string]int)
+	t.root.walkLeaves(func(n *node) {
+		key, ok := keys[n]
+		if !ok {
+			return
+		}
+		if _, ok := offsets[key]; !ok {
+			offsets[key] = data.Len()
+			data.WriteString(key)
+		}
+	})
+
+	// 按广度优先顺序为内部节点编号
+	nodes := []*node{t.root}
+	numbers := map[*node]int{t.root: 0}
+	for i := 0; i < len(nodes); i++ {
+		for _, child := range nodes[i].children {
+			if !child.isLeaf() {
+				numbers[child] = len(nodes)
+				nodes = append(nodes, child)
+			}
+		}
+	}
+	nodeCount := len(nodes)
+
+	recordValue := func(child *node) uint64 {
+		if !child.isLeaf() {
+			return uint64(numbers[child])
+		}
+		key, ok := keys[child]
+		if !ok {
+			return uint64(nodeCount)
+		}
+		return uint64(nodeCount + dataSectionSeparatorSize + offsets[key])
+	}
+
+	maxValue := uint64(nodeCount + dataSectionSeparatorSize + data.Len())
+	var recordSize int
+	switch {
+	case maxValue < 1<<24:
+		recordSize = 24
+	case maxValue < 1<<28:
+		recordSize = 28
+	case maxValue < 1<<32:
+		recordSize = 32
+	default:
+		return 0, fmt.Errorf("数据库过大: %d", maxValue)
+	}
+
+	cw := &countingWriter{w: w}
+
+	// 写入搜索树
+	buf := make([]byte, recordSize/4)
+	for _, n := range nodes {
+		putNode(buf, recordSize, recordValue(n.children[0]), recordValue(n.children[1]))
+		if _, err := cw.Write(buf); err != nil {
+			return cw.n, err
+		}
+	}
+
+	// 写入分隔符和数据段
+	if _, err := cw.Write(make([]byte, dataSectionSeparatorSize)); err != nil {
+		return cw.n, err
+	}
+	if _, err := cw.Write(data.Bytes()); err != nil {
+		return cw.n, err
+	}
+
+	// 写入元数据
+	metadata, err := t.encodeMetadata(nodeCount, recordSize)
+	if err != nil {
+		return cw.n, err
+	}
+	if _, err := cw.Write(metadataStartMarker); err != nil {
+		return cw.n, err
+	}
+	if _, err := cw.Write(metadata); err != nil {
+		return cw.n, err
+	}
+
+	return cw.n, nil
+}
+
+// encodeLeaves 编码所有叶子节点的数据
+func encodeLeaves(n *node, keys map[*node]string) error {
+	if !n.isLeaf() {
+		if err := encodeLeaves(n.children[0], keys); err != nil {
+			return err
+		}
+		return encodeLeaves(n.children[1], keys)
+	}
+	if n.value == nil {
+		return nil
+	}
+
+	encoded := &bytes.Buffer{}
+	if err := encode(encoded, n.value); err != nil {
+		return err
+	}
+	keys[n] = encoded.String()
+	return nil
+}
+
+// collapse 将两个子节点数据相同的内部节点合并为叶子节点
+func collapse(n *node, keys map[*node]string) {
+	if n.isLeaf() {
+		return
+	}
+	collapse(n.children[0], keys)
+	collapse(n.children[1], keys)
+
+	left, right := n.children[0], n.children[1]
+	if !left.isLeaf() || !right.isLeaf() {
+		return
+	}
+	leftKey, leftOK := keys[left]
+	rightKey, rightOK := keys[right]
+	if leftOK != rightOK || leftKey != rightKey {
+		return
+	}
+
+	n.children = [2]*node{}
+	n.value = left.value
+	if leftOK {
+		keys[n] = leftKey
+	}
+}
+
+// walkLeaves 按地址顺序遍历叶子节点
+func (n *node) walkLeaves(f func(*node)) {
+	if n.isLeaf() {
+		f(n)
+		return
+	}
+	n.children[0].walkLeaves(f)
+	n.children[1].walkLeaves(f)
+}
+
+// encodeMetadata 编码元数据
+func (t *Tree) encodeMetadata(nodeCount, recordSize int) ([]byte, error) {
+	buildEpoch := t.Metadata.BuildEpoch
+	if buildEpoch == 0 {
+		buildEpoch = time.Now().Unix()
+	}
+
+	description := make(map[string]any, len(t.Metadata.Description))
+	for lang, text := range t.Metadata.Description {
+		description[lang] = text
+	}
+	if len(description) == 0 {
+		description["en"] = t.Metadata.DatabaseType
+	}
+	languages := make([]any, 0, len(t.Metadata.Languages))
+	for _, lang := range t.Metadata.Languages {
+		languages = append(languages, lang)
+	}
+
+	buf := &bytes.Buffer{}
+	err := encode(buf, map[string]any{
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(buildEpoch),
+		"database_type":               t.Metadata.DatabaseType,
+		"description":                 description,
+		"ip_version":                  uint16(t.Metadata.IPVersion),
+		"languages":                   languages,
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(recordSize),
+	})
+	return buf.Bytes(), err
+}
+
+// putNode 按记录大小写入一个节点的左右记录
+func putNode(buf []byte, recordSize int, left, right uint64) {
+	switch recordSize {
+	case 24:
+		buf[0], buf[1], buf[2] = byte(left>>16), byte(left>>8), byte(left)
+		buf[3], buf[4], buf[5] = byte(right>>16), byte(right>>8), byte(right)
+	case 28:
+		buf[0], buf[1], buf[2] = byte(left>>16), byte(left>>8), byte(left)
+		buf[3] = byte((left>>24)&0x0F)<<4 | byte((right>>24)&0x0F)
+		buf[4], buf[5], buf[6] = byte(right>>16), byte(right>>8), byte(right)
+	case 32:
+		buf[0], buf[1], buf[2], buf[3] = byte(left>>24), byte(left>>16), byte(left>>8), byte(left)
+		buf[4], buf[5], buf[6], buf[7] = byte(right>>24), byte(right>>16), byte(right>>8), byte(right)
+	}
+}
+
+// bitAt 返回地址第i位的值
+func bitAt(ip net.IP, i int) int {
+	return int(ip[i>>3]>>(7-uint(i&7))) & 1
+}
+
+// countingWriter 统计写入字节数
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+// Write 实现io.Writer接口
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
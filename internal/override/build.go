@@ -0,0 +1,162 @@
+package override
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+
+	"ip-geo/internal/mmdbwriter"
+	"ip-geo/internal/provider"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// DatabaseType 编译生成的自定义数据库类型，可被provider.DetectSchema识别
+const DatabaseType = "IPGeo-Custom"
+
+// Build 将覆盖数据编译为MMDB搜索树
+//
+// 网段按在搜索树中的深度从浅到深插入，更具体的网段覆盖其所在的大网段，
+// 与Store的最长前缀匹配结果一致。IPv4网段位于::/96之下，深度为前缀长度加96，
+// 因此::/96以内的IPv6网段先于IPv4网段插入，不会覆盖IPv4子树。
+func Build(entries []*Entry, description string) (*mmdbwriter.Tree, error) {
+	metadata := mmdbwriter.Metadata{
+		DatabaseType: DatabaseType,
+		Languages:    []string{"zh-CN"},
+	}
+	if description != "" {
+		metadata.Description = map[string]string{"en": description}
+	}
+	tree := mmdbwriter.New(metadata)
+
+	sorted := slices.Clone(entries)
+	slices.SortStableFunc(sorted, func(a, b *Entry) int {
+		return treeDepth(a.prefix) - treeDepth(b.prefix)
+	})
+
+	for _, entry := range sorted {
+		if err := tree.Insert(toIPNet(entry.prefix), entry.value()); err != nil {
+			return nil, fmt.Errorf("插入网段 %s 失败: %v", entry.Network, err)
+		}
+	}
+	return tree, nil
+}
+
+// treeDepth 网段在搜索树中的深度
+func treeDepth(prefix netip.Prefix) int {
+	if prefix.Addr().Is4() {
+		return prefix.Bits() + 96
+	}
+	return prefix.Bits()
+}
+
+// VerifyFile 重新打开编译生成的数据库，校验文件结构并逐个网段核对查询结果
+func VerifyFile(path string, entries []*Entry) error {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开数据库失败: %v", err)
+	}
+	defer reader.Close()
+
+	if err := reader.Verify(); err != nil {
+		return fmt.Errorf("数据库结构校验失败: %v", err)
+	}
+
+	p, err := provider.New(provider.NameCustom, reader, "")
+	if err != nil {
+		return err
+	}
+
+	t := newTrie()
+	for _, entry := range entries {
+		t.insert(entry.prefix, entry)
+	}
+
+	// 分别检查每个网段的首尾地址，期望值为覆盖数据中的最长匹配
+	for _, entry := range entries {
+		for _, addr := range []netip.Addr{entry.prefix.Addr(), lastAddr(entry.prefix)} {
+			expected := t.lookup(addr).record()
+			actual, err := p.Lookup(net.IP(addr.AsSlice()))
+			if err != nil {
+				return fmt.Errorf("查询 %s 失败: %v", addr, err)
+			}
+			if actual == nil {
+				return fmt.Errorf("%s 没有匹配记录", addr)
+			}
+			if err := compareRecord(expected, actual); err != nil {
+				return fmt.Errorf("%s 的查询结果不一致: %v", addr, err)
+			}
+		}
+	}
+	return nil
+}
+
+// value 将覆盖数据转换为写入数据库的记录，省略空字段
+func (e *Entry) value() map[string]any {
+	value := make(map[string]any)
+	fields := []struct {
+		key   string
+		value string
+	}{
+		{"country_code", e.CountryCode},
+		{"country", e.Country},
+		{"region_code", e.RegionCode},
+		{"region", e.Region},
+		{"city", e.City},
+		{"isp", e.ISP},
+	}
+	for _, f := range fields {
+		if f.value != "" {
+			value[f.key] = f.value
+		}
+	}
+	if len(e.Tags) > 0 {
+		value["tags"] = e.Tags
+	}
+	return value
+}
+
+// compareRecord 比较覆盖数据与数据库查询得到的记录
+//
+// 写入时相邻的相同记录会被合并，因此不比较网段本身。
+func compareRecord(expected, actual *provider.Record) error {
+	pairs := []struct {
+		name             string
+		expected, actual string
+	}{
+		{"country_code", expected.CountryCode, actual.CountryCode},
+		{"country", expected.CountryName, actual.CountryName},
+		{"region_code", expected.RegionCode, actual.RegionCode},
+		{"region", expected.RegionName, actual.RegionName},
+		{"city", expected.CityName, actual.CityName},
+		{"isp", expected.ISPName, actual.ISPName},
+	}
+	for _, pair := range pairs {
+		if pair.expected != pair.actual {
+			return fmt.Errorf("%s 期望 %q，实际 %q", pair.name, pair.expected, pair.actual)
+		}
+	}
+	if !slices.Equal(expected.Tags, actual.Tags) {
+		return fmt.Errorf("tags 期望 %v，实际 %v", expected.Tags, actual.Tags)
+	}
+	return nil
+}
+
+// toIPNet 将netip.Prefix转换为net.IPNet
+func toIPNet(prefix netip.Prefix) *net.IPNet {
+	return &net.IPNet{
+		IP:   net.IP(prefix.Addr().AsSlice()),
+		Mask: net.CIDRMask(prefix.Bits(), prefix.Addr().BitLen()),
+	}
+}
+
+// lastAddr 返回网段中的最后一个地址
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	b := prefix.Addr().AsSlice()
+	for i := prefix.Bits(); i < len(b)*8; i++ {
+		b[i>>3] |= 1 << (7 - uint(i&7))
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
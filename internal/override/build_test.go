@@ -0,0 +1,175 @@
+package override
+
+import (
+	"bytes"
+	"net"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// testRecord 编译生成的数据库中的记录
+type testRecord struct {
+	CountryCode string   `maxminddb:"country_code"`
+	Region      string   `maxminddb:"region"`
+	City        string   `maxminddb:"city"`
+	ISP         string   `maxminddb:"isp"`
+	Tags        []string `maxminddb:"tags"`
+}
+
+func testEntries(t *testing.T) []*Entry {
+	t.Helper()
+	entries := []*Entry{
+		// 更具体的网段排在前面，检查Build按前缀长度排序
+		{Network: "10.1.2.3", ISP: "专线", CountryCode: "CN"},
+		{Network: "10.1.0.0/16", City: "办公区", CountryCode: "CN", Tags: []string{"office"}},
+		{Network: "10.0.0.0/8", Region: "内网", CountryCode: "CN"},
+		{Network: "2001:db8:1::/48", City: "实验室", CountryCode: "US"},
+		{Network: "2001:db8::/32", CountryCode: "US"},
+	}
+	for _, e := range entries {
+		prefix, err := parsePrefix(e.Network)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.prefix = prefix
+	}
+	return entries
+}
+
+// buildReader 编译覆盖数据并从内存中打开
+func buildReader(t *testing.T, entries []*Entry) *maxminddb.Reader {
+	t.Helper()
+	tree, err := Build(entries, "test")
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := tree.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	reader, err := maxminddb.FromBytes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("FromBytes: %v", err)
+	}
+	t.Cleanup(func() { reader.Close() })
+	if err := reader.Verify(); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	return reader
+}
+
+func TestBuildRoundTrip(t *testing.T) {
+	reader := buildReader(t, testEntries(t))
+	if reader.Metadata.DatabaseType != DatabaseType {
+		t.Errorf("DatabaseType = %q, want %q", reader.Metadata.DatabaseType, DatabaseType)
+	}
+
+	tests := []struct {
+		ip         string
+		want       testRecord
+		minPrefix  int
+		maxPrefix  int
+		wantsMatch bool
+	}{
+		{ip: "10.1.2.3", want: testRecord{CountryCode: "CN", ISP: "专线"}, minPrefix: 32, maxPrefix: 32, wantsMatch: true},
+		{ip: "10.1.200.1", want: testRecord{CountryCode: "CN", City: "办公区", Tags: []string{"office"}}, minPrefix: 16, maxPrefix: 32, wantsMatch: true},
+		{ip: "10.200.0.1", want: testRecord{CountryCode: "CN", Region: "内网"}, minPrefix: 8, maxPrefix: 16, wantsMatch: true},
+		{ip: "2001:db8:1::42", want: testRecord{CountryCode: "US", City: "实验室"}, minPrefix: 48, maxPrefix: 48, wantsMatch: true},
+		{ip: "2001:db8:ffff::1", want: testRecord{CountryCode: "US"}, minPrefix: 32, maxPrefix: 48, wantsMatch: true},
+		// IPv4映射地址查询IPv4子树
+		{ip: "::ffff:10.1.5.5", want: testRecord{CountryCode: "CN", City: "办公区", Tags: []string{"office"}}, minPrefix: 16, maxPrefix: 32, wantsMatch: true},
+		{ip: "192.0.2.1"},
+		{ip: "2001:db9::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			ip := net.ParseIP(tt.ip)
+			var got testRecord
+			network, ok, err := reader.LookupNetwork(ip, &got)
+			if err != nil {
+				t.Fatalf("LookupNetwork: %v", err)
+			}
+			if ok != tt.wantsMatch {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantsMatch)
+			}
+			if !ok {
+				return
+			}
+			if !network.Contains(ip) {
+				t.Errorf("network %s does not contain %s", network, ip)
+			}
+			if ones, _ := network.Mask.Size(); ones < tt.minPrefix || ones > tt.maxPrefix {
+				t.Errorf("network %s prefix length not in [%d, %d]", network, tt.minPrefix, tt.maxPrefix)
+			}
+			if got.CountryCode != tt.want.CountryCode || got.Region != tt.want.Region ||
+				got.City != tt.want.City || got.ISP != tt.want.ISP || !slices.Equal(got.Tags, tt.want.Tags) {
+				t.Errorf("record = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyFile(t *testing.T) {
+	entries := testEntries(t)
+	tree, err := Build(entries, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "custom.mmdb")
+	if err := tree.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyFile(path, entries); err != nil {
+		t.Errorf("VerifyFile: %v", err)
+	}
+
+	// 与数据库内容不一致的覆盖数据应当校验失败
+	entries[0].ISP = "其他"
+	if err := VerifyFile(path, entries); err == nil {
+		t.Error("VerifyFile succeeded with mismatched entries")
+	}
+}
+
+// TestBuildIPv6PrefixAboveIPv4 包含IPv4子树的IPv6网段不能覆盖更具体的IPv4网段
+func TestBuildIPv6PrefixAboveIPv4(t *testing.T) {
+	entries := testEntries(t)
+	for _, network := range []string{"::/64", "::/0"} {
+		prefix, err := parsePrefix(network)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, &Entry{Network: network, CountryCode: "ZZ", prefix: prefix})
+	}
+	reader := buildReader(t, entries)
+
+	for ip, want := range map[string]string{
+		"10.1.2.3":    "CN",
+		"10.200.0.1":  "CN",
+		"2001:db8::1": "US",
+		"::1":         "ZZ",
+		"2001:db9::1": "ZZ",
+	} {
+		var got testRecord
+		if err := reader.Lookup(net.ParseIP(ip), &got); err != nil {
+			t.Fatalf("Lookup(%s): %v", ip, err)
+		}
+		if got.CountryCode != want {
+			t.Errorf("%s country_code = %q, want %q", ip, got.CountryCode, want)
+		}
+	}
+
+	tree, err := Build(entries, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "custom.mmdb")
+	if err := tree.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyFile(path, entries); err != nil {
+		t.Errorf("VerifyFile: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package override
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// csvColumns CSV文件支持的列名
+var csvColumns = map[string]func(*Entry, string){
+	"network":      func(e *Entry, v string) { e.Network = v },
+	"country_code": func(e *Entry, v string) { e.CountryCode = v },
+	"country":      func(e *Entry, v string) { e.Country = v },
+	"region_code":  func(e *Entry, v string) { e.RegionCode = v },
+	"region":       func(e *Entry, v string) { e.Region = v },
+	"city":         func(e *Entry, v string) { e.City = v },
+	"isp":          func(e *Entry, v string) { e.ISP = v },
+	"tags":         func(e *Entry, v string) { e.Tags = splitTags(v) },
+}
+
+// LoadFile 根据扩展名加载YAML、JSON或CSV格式的覆盖文件并解析网段
+func LoadFile(path string) ([]*Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开覆盖文件失败: %v", err)
+	}
+	defer f.Close()
+
+	var entries []*Entry
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.NewDecoder(f).Decode(&entries); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("解析YAML覆盖文件失败: %v", err)
+		}
+	case ".json":
+		if err := json.NewDecoder(f).Decode(&entries); err != nil {
+			return nil, fmt.Errorf("解析JSON覆盖文件失败: %v", err)
+		}
+	case ".csv":
+		entries, err = ReadCSV(f)
+		if err != nil {
+			return nil, fmt.Errorf("解析CSV覆盖文件失败: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("不支持的覆盖文件格式: %s", ext)
+	}
+
+	for i, entry := range entries {
+		prefix, err := parsePrefix(entry.Network)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 条覆盖数据无效: %v", i+1, err)
+		}
+		entry.prefix = prefix
+	}
+	return entries, nil
+}
+
+// ReadCSV 读取CSV格式的覆盖数据
+//
+// 第一行为表头，必须包含network列；tags列中多个标签以;或|分隔。
+func ReadCSV(r io.Reader) ([]*Entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	setters := make([]func(*Entry, string), len(header))
+	hasNetwork := false
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		setters[i] = csvColumns[column]
+		if column == "network" {
+			hasNetwork = true
+		}
+	}
+	if !hasNetwork {
+		return nil, fmt.Errorf("缺少network列")
+	}
+
+	var entries []*Entry
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entry := &Entry{}
+		for i, value := range row {
+			if i < len(setters) && setters[i] != nil {
+				setters[i](entry, strings.TrimSpace(value))
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// splitTags 拆分以;或|分隔的标签
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == '|' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,117 @@
+// Package override 实现本地网段覆盖数据
+//
+// 内网地址、办公出口和合作方网段在公共数据库中通常为空或不准确，
+// 覆盖文件按网段指定国家、地区、城市、ISP和自定义标签，查询时优先于MMDB数据源。
+package override
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"sync"
+
+	"ip-geo/internal/logger"
+	"ip-geo/internal/provider"
+)
+
+// Entry 单个网段的覆盖数据
+type Entry struct {
+	Network     string   `json:"network" yaml:"network"`
+	CountryCode string   `json:"country_code" yaml:"country_code"`
+	Country     string   `json:"country" yaml:"country"`
+	RegionCode  string   `json:"region_code" yaml:"region_code"`
+	Region      string   `json:"region" yaml:"region"`
+	City        string   `json:"city" yaml:"city"`
+	ISP         string   `json:"isp" yaml:"isp"`
+	Tags        []string `json:"tags" yaml:"tags"`
+
+	prefix netip.Prefix
+}
+
+// Prefix 返回解析后的网段
+func (e *Entry) Prefix() netip.Prefix {
+	return e.prefix
+}
+
+// record 将覆盖数据转换为数据源记录
+func (e *Entry) record() *provider.Record {
+	return &provider.Record{
+		CountryCode: e.CountryCode,
+		CountryName: e.Country,
+		RegionCode:  e.RegionCode,
+		RegionName:  e.Region,
+		CityName:    e.City,
+		ISPName:     e.ISP,
+		Network:     toIPNet(e.prefix),
+		Tags:        e.Tags,
+	}
+}
+
+// Store 覆盖数据存储，实现provider.Provider接口
+type Store struct {
+	path string
+
+	mu   sync.RWMutex
+	trie *trie
+}
+
+// NewStore 创建新的Store实例，数据需通过Reload加载
+func NewStore(path string) *Store {
+	return &Store{
+		path: path,
+		trie: newTrie(),
+	}
+}
+
+// Name 实现provider.Provider接口
+func (s *Store) Name() string {
+	return provider.NameOverride
+}
+
+// Lookup 实现provider.Provider接口
+func (s *Store) Lookup(ip net.IP) (*provider.Record, error) {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return nil, nil
+	}
+
+	s.mu.RLock()
+	entry := s.trie.lookup(addr)
+	s.mu.RUnlock()
+	if entry == nil {
+		return nil, nil
+	}
+	return entry.record(), nil
+}
+
+// Reload 重新加载覆盖文件，加载失败时保留原有数据
+func (s *Store) Reload() error {
+	entries, err := LoadFile(s.path)
+	if err != nil {
+		return err
+	}
+
+	t := newTrie()
+	for _, entry := range entries {
+		t.insert(entry.prefix, entry)
+	}
+
+	s.mu.Lock()
+	s.trie = t
+	s.mu.Unlock()
+
+	logger.Info("加载覆盖数据 %s: %d 个网段", s.path, t.size)
+	return nil
+}
+
+// parsePrefix 解析CIDR网段，单个IP视为/32或/128
+func parsePrefix(value string) (netip.Prefix, error) {
+	if prefix, err := netip.ParsePrefix(value); err == nil {
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("无效的网段: %s", value)
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
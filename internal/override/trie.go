@@ -0,0 +1,70 @@
+package override
+
+import "net/netip"
+
+// trie 按位存储网段的前缀树，用于最长前缀匹配
+//
+// IPv4网段以IPv4映射地址(::ffff:0:0/96)的形式存储，与IPv6共用一棵树。
+type trie struct {
+	root *trieNode
+	size int
+}
+
+// trieNode 前缀树节点
+type trieNode struct {
+	children [2]*trieNode
+	entry    *Entry
+}
+
+// newTrie 创建空的前缀树
+func newTrie() *trie {
+	return &trie{root: &trieNode{}}
+}
+
+// insert 插入网段，相同网段后插入的覆盖先插入的
+func (t *trie) insert(prefix netip.Prefix, entry *Entry) {
+	addr, bits := to16(prefix.Addr()), prefix.Bits()
+	if prefix.Addr().Is4() {
+		bits += 96
+	}
+
+	n := t.root
+	key := addr.As16()
+	for i := 0; i < bits; i++ {
+		bit := key[i>>3] >> (7 - uint(i&7)) & 1
+		if n.children[bit] == nil {
+			n.children[bit] = &trieNode{}
+		}
+		n = n.children[bit]
+	}
+	if n.entry == nil {
+		t.size++
+	}
+	n.entry = entry
+}
+
+// lookup 返回包含该地址的最长匹配网段
+func (t *trie) lookup(addr netip.Addr) *Entry {
+	key := to16(addr).As16()
+
+	var match *Entry
+	n := t.root
+	for i := 0; n != nil; i++ {
+		if n.entry != nil {
+			match = n.entry
+		}
+		if i == 128 {
+			break
+		}
+		n = n.children[key[i>>3]>>(7-uint(i&7))&1]
+	}
+	return match
+}
+
+// to16 将地址统一转换为16字节形式
+func to16(addr netip.Addr) netip.Addr {
+	if addr.Is4() {
+		return netip.AddrFrom16(addr.As16())
+	}
+	return addr
+}
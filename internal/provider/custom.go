@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// decodeCustom 解码由覆盖数据编译生成的自定义数据库记录
+//
+// 记录为扁平结构，字段名与覆盖文件一致，参见cmd/mmdb-build。
+func decodeCustom(reader *maxminddb.Reader, ip net.IP) (*Record, error) {
+	var customRecord struct {
+		CountryCode string   `maxminddb:"country_code"`
+		Country     string   `maxminddb:"country"`
+		RegionCode  string   `maxminddb:"region_code"`
+		Region      string   `maxminddb:"region"`
+		City        string   `maxminddb:"city"`
+		ISP         string   `maxminddb:"isp"`
+		Tags        []string `maxminddb:"tags"`
+	}
+
+	network, ok, err := reader.LookupNetwork(ip, &customRecord)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return &Record{
+		CountryCode: customRecord.CountryCode,
+		CountryName: customRecord.Country,
+		RegionCode:  customRecord.RegionCode,
+		RegionName:  customRecord.Region,
+		CityName:    customRecord.City,
+		ISPName:     customRecord.ISP,
+		Network:     network,
+		Tags:        customRecord.Tags,
+	}, nil
+}
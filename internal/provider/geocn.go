@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// geoCNRecord GeoCN格式的中国IP记录
+type geoCNRecord struct {
+	Province      string `maxminddb:"province"`
+	ProvinceCode  uint64 `maxminddb:"provinceCode"`
+	City          string `maxminddb:"city"`
+	CityCode      uint64 `maxminddb:"cityCode"`
+	Districts     string `maxminddb:"districts"`
+	DistrictsCode uint64 `maxminddb:"districtsCode"`
+	ISP           string `maxminddb:"isp"`
+	Net           string `maxminddb:"net"`
+}
+
+// decodeGeoCN 解码GeoCN格式的中国IP记录
+func decodeGeoCN(reader *maxminddb.Reader, ip net.IP) (*Record, error) {
+	var cnRecord geoCNRecord
+
+	network, ok, err := reader.LookupNetwork(ip, &cnRecord)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return cnRecord.toRecord(network), nil
+}
+
+// geoCNExclusive 中国IP以GeoCN为准的字段
+//
+// 地区名称已经包含省市区，不单独设置城市；GeoCN没有的地区和城市不从GeoIP2 City补充。
+var geoCNExclusive = []Field{
+	FieldCountry,
+	FieldRegion,
+	FieldCity,
+	FieldTimeZone,
+}
+
+// toRecord 将GeoCN记录转换为Record，不是有效的中国IP记录时返回nil
+func (r *geoCNRecord) toRecord(network *net.IPNet) *Record {
+	// 只有当Province或ISP字段不为空时才认为是有效的中国IP记录
+	if r.Province == "" && r.ISP == "" {
+		return nil
+	}
+
+	record := &Record{
+		CountryCode: "CN",
+		CountryName: "中国",
+		TimeZone:    "Asia/Shanghai",
+		ISPName:     r.ISP,
+		ASNInfo:     r.ISP,
+		NetworkType: r.Net,
+		Network:     network,
+		Exclusive:   geoCNExclusive,
+	}
+
+	// 处理地区信息
+	regions := []string{r.Province, r.City, r.Districts}
+	record.RegionName = strings.Join(removeEmpty(regions), "")
+
+	// 设置地区代码
+	if r.Districts != "" {
+		record.RegionCode = fmt.Sprintf("%d", r.DistrictsCode)
+	} else if r.City != "" {
+		record.RegionCode = fmt.Sprintf("%d", r.CityCode)
+	} else if r.Province != "" {
+		record.RegionCode = fmt.Sprintf("%d", r.ProvinceCode)
+	}
+
+	return record
+}
@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"net"
+
+	"ip-geo/pkg/asn"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// decodeIP2Location 解码IP2Location LITE格式的记录
+//
+// IP2Location发布的MMDB文件既有与GeoIP2兼容的版本，也有沿用BIN列名的版本，
+// 后者以country_short/country_long等字段存储，未知值为"-"。
+func decodeIP2Location(reader *maxminddb.Reader, ip net.IP) (*Record, error) {
+	var ip2locationRecord struct {
+		CountryShort string `maxminddb:"country_short"`
+		CountryLong  string `maxminddb:"country_long"`
+		Region       string `maxminddb:"region"`
+		City         string `maxminddb:"city"`
+		Latitude     any    `maxminddb:"latitude"`
+		Longitude    any    `maxminddb:"longitude"`
+		TimeZone     string `maxminddb:"time_zone"`
+		ISP          string `maxminddb:"isp"`
+		ASN          any    `maxminddb:"asn"`
+		AS           string `maxminddb:"as"`
+		UsageType    string `maxminddb:"usage_type"`
+	}
+
+	network, ok, err := reader.LookupNetwork(ip, &ip2locationRecord)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	// 与GeoIP2兼容的版本按City格式解码
+	if ip2locationRecord.CountryShort == "" {
+		return decodeGeoIP2City(reader, ip)
+	}
+
+	record := &Record{
+		CountryCode: unknownToEmpty(ip2locationRecord.CountryShort),
+		CountryName: unknownToEmpty(ip2locationRecord.CountryLong),
+		RegionName:  unknownToEmpty(ip2locationRecord.Region),
+		CityName:    unknownToEmpty(ip2locationRecord.City),
+		Latitude:    parseFloat(ip2locationRecord.Latitude),
+		Longitude:   parseFloat(ip2locationRecord.Longitude),
+		TimeZone:    unknownToEmpty(ip2locationRecord.TimeZone),
+		ISPName:     unknownToEmpty(ip2locationRecord.ISP),
+		NetworkType: unknownToEmpty(ip2locationRecord.UsageType),
+		Network:     network,
+	}
+
+	if number := parseASN(ip2locationRecord.ASN); number != 0 {
+		record.ASNNumber = number
+		record.ASNName = unknownToEmpty(ip2locationRecord.AS)
+		if info, ok := asn.Map[int(number)]; ok {
+			record.ASNInfo = info
+			record.ISPType = info
+		}
+	}
+
+	return record, nil
+}
+
+// unknownToEmpty 将IP2Location表示未知的"-"转换为空字符串
+func unknownToEmpty(value string) string {
+	if value == "-" {
+		return ""
+	}
+	return value
+}
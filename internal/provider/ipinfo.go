@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"ip-geo/pkg/asn"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// decodeIPinfo 解码IPinfo格式的记录
+//
+// IPinfo的各个数据库字段并不统一：旧版country/country_asn中country为国家代码、
+// country_name为名称，Lite版中country_code为代码、country为名称；
+// asn字段为"AS15169"形式的字符串；经纬度可能以字符串存储。
+func decodeIPinfo(reader *maxminddb.Reader, ip net.IP) (*Record, error) {
+	var ipinfoRecord struct {
+		Country       string `maxminddb:"country"`
+		CountryCode   string `maxminddb:"country_code"`
+		CountryName   string `maxminddb:"country_name"`
+		Continent     string `maxminddb:"continent"`
+		ContinentCode string `maxminddb:"continent_code"`
+		ContinentName string `maxminddb:"continent_name"`
+		Region        string `maxminddb:"region"`
+		RegionCode    string `maxminddb:"region_code"`
+		City          string `maxminddb:"city"`
+		Latitude      any    `maxminddb:"latitude"`
+		Longitude     any    `maxminddb:"longitude"`
+		Timezone      string `maxminddb:"timezone"`
+		ASN           any    `maxminddb:"asn"`
+		Name          string `maxminddb:"name"`
+		ASName        string `maxminddb:"as_name"`
+		Type          string `maxminddb:"type"`
+	}
+
+	network, ok, err := reader.LookupNetwork(ip, &ipinfoRecord)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	record := &Record{
+		RegionCode:  ipinfoRecord.RegionCode,
+		RegionName:  ipinfoRecord.Region,
+		CityName:    ipinfoRecord.City,
+		Latitude:    parseFloat(ipinfoRecord.Latitude),
+		Longitude:   parseFloat(ipinfoRecord.Longitude),
+		TimeZone:    ipinfoRecord.Timezone,
+		NetworkType: ipinfoRecord.Type,
+		Network:     network,
+	}
+
+	if ipinfoRecord.CountryCode != "" {
+		record.CountryCode = ipinfoRecord.CountryCode
+		record.CountryName = ipinfoRecord.Country
+	} else {
+		record.CountryCode = ipinfoRecord.Country
+		record.CountryName = ipinfoRecord.CountryName
+	}
+
+	if ipinfoRecord.ContinentCode != "" {
+		record.ContinentCode = ipinfoRecord.ContinentCode
+		record.ContinentName = ipinfoRecord.Continent
+	} else {
+		record.ContinentCode = ipinfoRecord.Continent
+		record.ContinentName = ipinfoRecord.ContinentName
+	}
+
+	// asn.mmdb中AS名称为name，country_asn和Lite版中为as_name
+	asName := ipinfoRecord.ASName
+	if asName == "" {
+		asName = ipinfoRecord.Name
+	}
+	if number := parseASN(ipinfoRecord.ASN); number != 0 {
+		record.ASNNumber = number
+		record.ASNName = asName
+		record.ISPName = asName
+		if info, ok := asn.Map[int(number)]; ok {
+			record.ASNInfo = info
+			record.ISPType = info
+		}
+	}
+
+	return record, nil
+}
+
+// parseASN 解析"AS15169"形式或数值形式的ASN
+func parseASN(value any) uint {
+	switch v := value.(type) {
+	case string:
+		number, err := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(v), "AS"), 10, 32)
+		if err != nil {
+			return 0
+		}
+		return uint(number)
+	case uint64:
+		return uint(v)
+	case int:
+		if v > 0 {
+			return uint(v)
+		}
+	}
+	return 0
+}
+
+// parseFloat 解析数值或字符串形式的浮点数
+func parseFloat(value any) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0
+		}
+		return f
+	}
+	return 0
+}
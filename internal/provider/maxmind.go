@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"net"
+
+	"ip-geo/internal/logger"
+	"ip-geo/pkg/asn"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// decodeGeoIP2ASN 解码GeoLite2-ASN/GeoIP2-ISP格式的记录
+func decodeGeoIP2ASN(reader *maxminddb.Reader, ip net.IP) (*Record, error) {
+	var asnRecord struct {
+		AutonomousSystemNumber       uint   `maxminddb:"autonomous_system_number"`
+		AutonomousSystemOrganization string `maxminddb:"autonomous_system_organization"`
+		ISP                          string `maxminddb:"isp"`
+		ConnectionType               string `maxminddb:"connection_type"`
+	}
+
+	network, ok, err := reader.LookupNetwork(ip, &asnRecord)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	record := &Record{
+		ASNNumber:   asnRecord.AutonomousSystemNumber,
+		ASNName:     asnRecord.AutonomousSystemOrganization,
+		ISPName:     asnRecord.AutonomousSystemOrganization,
+		NetworkType: asnRecord.ConnectionType,
+		Network:     network,
+	}
+	if asnRecord.ISP != "" {
+		record.ISPName = asnRecord.ISP
+	}
+	if info, ok := asn.Map[int(asnRecord.AutonomousSystemNumber)]; ok {
+		record.ASNInfo = info
+		record.ISPType = info
+	}
+	return record, nil
+}
+
+// geoIP2CityRecord GeoIP2/GeoLite2-City和Country格式的记录
+type geoIP2CityRecord struct {
+	Continent struct {
+		Code      string            `maxminddb:"code"`
+		GeonameID uint32            `maxminddb:"geoname_id"`
+		Names     map[string]string `maxminddb:"names"`
+	} `maxminddb:"continent"`
+	Country struct {
+		GeonameID uint32            `maxminddb:"geoname_id"`
+		ISOCode   string            `maxminddb:"iso_code"`
+		Names     map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		GeonameID uint32            `maxminddb:"geoname_id"`
+		ISOCode   string            `maxminddb:"iso_code"`
+		Names     map[string]string `maxminddb:"names"`
+	} `maxminddb:"registered_country"`
+	City struct {
+		GeonameID uint32            `maxminddb:"geoname_id"`
+		Names     map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Subdivisions []struct {
+		GeonameID uint32            `maxminddb:"geoname_id"`
+		ISOCode   string            `maxminddb:"iso_code"`
+		Names     map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	Location struct {
+		AccuracyRadius uint16  `maxminddb:"accuracy_radius"`
+		Latitude       float64 `maxminddb:"latitude"`
+		Longitude      float64 `maxminddb:"longitude"`
+		TimeZone       string  `maxminddb:"time_zone"`
+	} `maxminddb:"location"`
+	Traits struct {
+		IsAnycast bool `maxminddb:"is_anycast"`
+	} `maxminddb:"traits"`
+}
+
+// decodeGeoIP2City 解码GeoIP2/GeoLite2-City和Country格式的记录
+func decodeGeoIP2City(reader *maxminddb.Reader, ip net.IP) (*Record, error) {
+	var cityRecord geoIP2CityRecord
+
+	network, ok, err := reader.LookupNetwork(ip, &cityRecord)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return cityRecord.toRecord(ip, network), nil
+}
+
+// toRecord 将GeoIP2 City记录转换为Record
+func (r *geoIP2CityRecord) toRecord(ip net.IP, network *net.IPNet) *Record {
+	record := &Record{
+		ContinentCode: r.Continent.Code,
+		ContinentName: getLocalizedName(r.Continent.Names, "zh-CN", "en"),
+		Network:       network,
+	}
+
+	// 对于Anycast IP，使用registered_country的信息
+	if r.Traits.IsAnycast {
+		logger.Debug("检测到Anycast IP: %s", ip)
+		record.CountryCode = r.RegisteredCountry.ISOCode
+		record.CountryName = getLocalizedName(r.RegisteredCountry.Names, "zh-CN", "en")
+
+		// Anycast IP通常不设置具体的地区和城市信息
+		return record
+	}
+
+	record.CountryCode = r.Country.ISOCode
+	record.CountryName = getLocalizedName(r.Country.Names, "zh-CN", "en")
+
+	if len(r.Subdivisions) > 0 {
+		subdivision := r.Subdivisions[0]
+		record.RegionCode = subdivision.ISOCode
+		record.RegionName = getLocalizedName(subdivision.Names, "zh-CN", "en")
+	}
+
+	record.CityName = getLocalizedName(r.City.Names, "zh-CN", "en")
+
+	// DB-IP等数据库可能只提供经纬度而没有时区
+	record.Latitude = r.Location.Latitude
+	record.Longitude = r.Location.Longitude
+	record.AccuracyRadius = r.Location.AccuracyRadius
+	record.TimeZone = r.Location.TimeZone
+
+	return record
+}
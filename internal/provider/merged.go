@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"net"
+	"slices"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// GeoCNKey 合并数据库中GeoCN记录所在的键
+const GeoCNKey = "geocn"
+
+// geoCNFields GeoCN数据优先于GeoIP2 City的字段，与默认合并策略一致
+var geoCNFields = []Field{
+	FieldASNInfo,
+	FieldCountry,
+	FieldRegion,
+	FieldCity,
+	FieldTimeZone,
+	FieldISP,
+	FieldNetworkType,
+}
+
+// decodeGeoIP2CityGeoCN 解码合并了GeoCN数据的GeoIP2 City记录
+//
+// 记录顶层为GeoIP2 City格式，GeoCN原始记录位于geocn键下，一次查询即可得到两者的数据。
+func decodeGeoIP2CityGeoCN(reader *maxminddb.Reader, ip net.IP) (*Record, error) {
+	var mergedRecord struct {
+		geoIP2CityRecord
+		GeoCN geoCNRecord `maxminddb:"geocn"`
+	}
+
+	network, ok, err := reader.LookupNetwork(ip, &mergedRecord)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return MergeGeoCN(mergedRecord.toRecord(ip, network), mergedRecord.GeoCN.toRecord(network)), nil
+}
+
+// MergeGeoCN 将GeoCN记录中的字段覆盖到GeoIP2 City记录上，返回新的记录
+//
+// 结果与分别查询两个数据库后按默认合并策略合并相同，两者都为nil时返回nil。
+func MergeGeoCN(city, geoCN *Record) *Record {
+	if geoCN == nil {
+		return city
+	}
+	if city == nil {
+		return geoCN
+	}
+
+	merged := *city
+	merged.Exclusive = append(slices.Clone(city.Exclusive), geoCN.Exclusive...)
+	for _, field := range geoCNFields {
+		if !geoCN.Has(field) {
+			continue
+		}
+		switch field {
+		case FieldASNInfo:
+			merged.ASNInfo = geoCN.ASNInfo
+		case FieldCountry:
+			merged.CountryCode = geoCN.CountryCode
+			merged.CountryName = geoCN.CountryName
+		case FieldRegion:
+			merged.RegionCode = geoCN.RegionCode
+			merged.RegionName = geoCN.RegionName
+		case FieldCity:
+			merged.CityName = geoCN.CityName
+		case FieldTimeZone:
+			merged.TimeZone = geoCN.TimeZone
+		case FieldISP:
+			merged.ISPName = geoCN.ISPName
+		case FieldNetworkType:
+			merged.NetworkType = geoCN.NetworkType
+		}
+	}
+	return &merged
+}
@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// 支持的数据库记录格式
+const (
+	SchemaGeoIP2ASN   = "geoip2-asn"
+	SchemaGeoIP2City  = "geoip2-city"
+	SchemaGeoCN       = "geocn"
+	SchemaIPinfo      = "ipinfo"
+	SchemaIP2Location = "ip2location"
+	SchemaCustom      = "ipgeo-custom"
+
+	SchemaGeoIP2CityGeoCN = "geoip2-city-geocn"
+
+	SchemaGeoIP2AnonymousIP = "geoip2-anonymous-ip"
+)
+
+// decodeFunc 从数据库查询IP并将记录转换为Record
+type decodeFunc func(reader *maxminddb.Reader, ip net.IP) (*Record, error)
+
+// decoders 各记录格式的解码函数
+var decoders = map[string]decodeFunc{
+	SchemaGeoIP2ASN:   decodeGeoIP2ASN,
+	SchemaGeoIP2City:  decodeGeoIP2City,
+	SchemaGeoCN:       decodeGeoCN,
+	SchemaIPinfo:      decodeIPinfo,
+	SchemaIP2Location: decodeIP2Location,
+	SchemaCustom:      decodeCustom,
+
+	SchemaGeoIP2CityGeoCN: decodeGeoIP2CityGeoCN,
+
+	SchemaGeoIP2AnonymousIP: decodeGeoIP2AnonymousIP,
+}
+
+// DetectSchema 根据Metadata.DatabaseType识别记录格式
+//
+// DB-IP的MMDB文件与MaxMind格式兼容，按ASN/City两类处理。
+func DetectSchema(databaseType string) (string, bool) {
+	t := strings.ToLower(databaseType)
+	switch {
+	case strings.HasPrefix(t, SchemaCustom):
+		return SchemaCustom, true
+	case strings.HasPrefix(t, "ipinfo"):
+		return SchemaIPinfo, true
+	case strings.HasPrefix(t, "ip2location"):
+		return SchemaIP2Location, true
+	case strings.Contains(t, "geocn") && strings.Contains(t, "city"):
+		// cmd/geocn-merge生成的GeoIP2-City-GeoCN
+		return SchemaGeoIP2CityGeoCN, true
+	case strings.Contains(t, "geocn"):
+		return SchemaGeoCN, true
+	case strings.Contains(t, "anonymous"):
+		return SchemaGeoIP2AnonymousIP, true
+	case strings.Contains(t, "asn"), strings.Contains(t, "isp"):
+		// GeoLite2-ASN、GeoIP2-ISP、DBIP-ASN-Lite、DBIP-ISP
+		return SchemaGeoIP2ASN, true
+	case strings.Contains(t, "city"), strings.Contains(t, "country"),
+		strings.Contains(t, "location"), strings.Contains(t, "enterprise"):
+		// GeoIP2-City、GeoLite2-Country、DBIP-City-Lite、DBIP-Location
+		return SchemaGeoIP2City, true
+	}
+	return "", false
+}
+
+// MMDBProvider 基于MMDB文件的数据源，支持运行时替换数据库
+type MMDBProvider struct {
+	name   string
+	schema string
+	decode decodeFunc
+
+	mu     sync.RWMutex
+	reader *maxminddb.Reader
+}
+
+// New 创建基于MMDB文件的数据源，schema为空时根据数据库类型自动识别
+func New(name string, reader *maxminddb.Reader, schema string) (*MMDBProvider, error) {
+	if reader == nil {
+		return nil, fmt.Errorf("数据源 %s 的数据库未打开", name)
+	}
+
+	if schema == "" {
+		detected, ok := DetectSchema(reader.Metadata.DatabaseType)
+		if !ok {
+			return nil, fmt.Errorf("无法识别数据源 %s 的数据库类型: %s", name, reader.Metadata.DatabaseType)
+		}
+		schema = detected
+	}
+
+	decode, ok := decoders[schema]
+	if !ok {
+		return nil, fmt.Errorf("不支持的记录格式: %s", schema)
+	}
+
+	return &MMDBProvider{
+		name:   name,
+		schema: schema,
+		reader: reader,
+		decode: decode,
+	}, nil
+}
+
+// NewPending 创建尚未加载数据库的数据源，加载前查询不返回结果，之后通过Replace加载数据库
+func NewPending(name, schema string) (*MMDBProvider, error) {
+	decode, ok := decoders[schema]
+	if !ok {
+		return nil, fmt.Errorf("不支持的记录格式: %s", schema)
+	}
+	return &MMDBProvider{
+		name:   name,
+		schema: schema,
+		decode: decode,
+	}, nil
+}
+
+// Name 实现Provider接口
+func (p *MMDBProvider) Name() string {
+	return p.name
+}
+
+// Schema 返回数据源使用的记录格式
+func (p *MMDBProvider) Schema() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.schema
+}
+
+// Loaded 判断数据库是否已加载
+func (p *MMDBProvider) Loaded() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.reader != nil
+}
+
+// Lookup 实现Provider接口，数据库未加载时返回nil
+func (p *MMDBProvider) Lookup(ip net.IP) (*Record, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.reader == nil {
+		return nil, nil
+	}
+	return p.decode(p.reader, ip)
+}
+
+// Replace 替换数据库和记录格式并返回原数据库
+//
+// 返回时已没有使用原数据库的查询，调用方可以直接关闭原数据库。
+func (p *MMDBProvider) Replace(reader *maxminddb.Reader, schema string) (*maxminddb.Reader, error) {
+	decode, ok := decoders[schema]
+	if !ok {
+		return nil, fmt.Errorf("不支持的记录格式: %s", schema)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	old := p.reader
+	p.reader = reader
+	p.schema = schema
+	p.decode = decode
+	return old, nil
+}
@@ -0,0 +1,171 @@
+// Package provider 定义IP数据源接口及其实现
+package provider
+
+import (
+	"net"
+	"slices"
+)
+
+// 内置数据源名称
+const (
+	NameASN   = "asn"
+	NameCity  = "city"
+	NameGeoCN = "geocn"
+
+	NameAnonymousIP = "anonymous_ip"
+	NameTorExitList = "tor_exit_list"
+	NameOverride    = "override"
+	NameCustom      = "custom"
+
+	// NameFallback 编译时内嵌的国家级数据库
+	NameFallback = "fallback"
+)
+
+// Field 可按数据源合并的查询结果字段
+type Field string
+
+// 可合并的字段
+const (
+	FieldASN         Field = "asn"
+	FieldASNInfo     Field = "asn_info"
+	FieldContinent   Field = "continent"
+	FieldCountry     Field = "country"
+	FieldRegion      Field = "region"
+	FieldCity        Field = "city"
+	FieldLocation    Field = "location"
+	FieldTimeZone    Field = "timezone"
+	FieldISP         Field = "isp"
+	FieldISPType     Field = "isp_type"
+	FieldNetworkType Field = "network_type"
+	FieldNetwork     Field = "network"
+)
+
+// Fields 所有可合并的字段，按合并顺序排列
+var Fields = []Field{
+	FieldASN,
+	FieldASNInfo,
+	FieldContinent,
+	FieldCountry,
+	FieldRegion,
+	FieldCity,
+	FieldLocation,
+	FieldTimeZone,
+	FieldISP,
+	FieldISPType,
+	FieldNetworkType,
+	FieldNetwork,
+}
+
+// 安全标记，与响应中security部分的字段名一致
+const (
+	FlagAnonymous        = "is_anonymous"
+	FlagAnonymousVPN     = "is_anonymous_vpn"
+	FlagHostingProvider  = "is_hosting_provider"
+	FlagPublicProxy      = "is_public_proxy"
+	FlagResidentialProxy = "is_residential_proxy"
+	FlagTorExitNode      = "is_tor_exit_node"
+)
+
+// Record 单个数据源返回的部分查询结果，数据源不提供的字段保持零值
+type Record struct {
+	ASNNumber uint
+	ASNName   string
+	ASNInfo   string
+
+	ContinentCode string
+	ContinentName string
+	CountryCode   string
+	CountryName   string
+	RegionCode    string
+	RegionName    string
+	CityName      string
+
+	Latitude       float64
+	Longitude      float64
+	AccuracyRadius uint16
+	TimeZone       string
+
+	ISPName     string
+	ISPType     string
+	NetworkType string
+
+	// Network 匹配到的网段
+	Network *net.IPNet
+
+	// Flags 数据源标记的安全属性，不参与字段合并，所有数据源的标记取并集
+	Flags []string
+	// Tags 自定义标签
+	Tags []string
+
+	// Exclusive 即使值为空也以该数据源为准的字段，合并时不再尝试后面的数据源
+	Exclusive []Field
+}
+
+// Has 判断记录是否包含指定字段，Exclusive中的字段总是包含
+func (r *Record) Has(field Field) bool {
+	if slices.Contains(r.Exclusive, field) {
+		return true
+	}
+	switch field {
+	case FieldASN:
+		return r.ASNNumber != 0
+	case FieldASNInfo:
+		return r.ASNInfo != ""
+	case FieldContinent:
+		return r.ContinentCode != ""
+	case FieldCountry:
+		return r.CountryCode != ""
+	case FieldRegion:
+		return r.RegionCode != "" || r.RegionName != ""
+	case FieldCity:
+		return r.CityName != ""
+	case FieldLocation:
+		return r.Latitude != 0 || r.Longitude != 0
+	case FieldTimeZone:
+		return r.TimeZone != ""
+	case FieldISP:
+		return r.ISPName != ""
+	case FieldISPType:
+		return r.ISPType != ""
+	case FieldNetworkType:
+		return r.NetworkType != ""
+	case FieldNetwork:
+		return r.Network != nil
+	}
+	return false
+}
+
+// Provider IP数据源
+type Provider interface {
+	// Name 数据源名称，合并策略通过名称引用数据源
+	Name() string
+	// Lookup 查询IP信息，没有匹配记录时返回nil
+	Lookup(ip net.IP) (*Record, error)
+}
+
+// Reloader 支持运行时重新加载数据的数据源
+type Reloader interface {
+	Reload() error
+}
+
+// getLocalizedName 获取本地化名称
+func getLocalizedName(names map[string]string, primaryLang, fallbackLang string) string {
+	if name, ok := names[primaryLang]; ok {
+		return name
+	}
+	if name, ok := names[fallbackLang]; ok {
+		return name
+	}
+	return ""
+}
+
+// removeEmpty 移除空字符串
+func removeEmpty(arr []string) []string {
+	result := make([]string, 0)
+	for _, str := range arr {
+		if str != "" {
+			result = append(result, str)
+		}
+	}
+	return result
+}
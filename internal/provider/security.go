@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
+
+	"ip-geo/internal/logger"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// decodeGeoIP2AnonymousIP 解码GeoIP2-Anonymous-IP格式的记录
+func decodeGeoIP2AnonymousIP(reader *maxminddb.Reader, ip net.IP) (*Record, error) {
+	var anonymousRecord struct {
+		IsAnonymous        bool `maxminddb:"is_anonymous"`
+		IsAnonymousVPN     bool `maxminddb:"is_anonymous_vpn"`
+		IsHostingProvider  bool `maxminddb:"is_hosting_provider"`
+		IsPublicProxy      bool `maxminddb:"is_public_proxy"`
+		IsResidentialProxy bool `maxminddb:"is_residential_proxy"`
+		IsTorExitNode      bool `maxminddb:"is_tor_exit_node"`
+	}
+
+	network, ok, err := reader.LookupNetwork(ip, &anonymousRecord)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	record := &Record{Network: network}
+	flags := []struct {
+		set  bool
+		flag string
+	}{
+		{anonymousRecord.IsAnonymous, FlagAnonymous},
+		{anonymousRecord.IsAnonymousVPN, FlagAnonymousVPN},
+		{anonymousRecord.IsHostingProvider, FlagHostingProvider},
+		{anonymousRecord.IsPublicProxy, FlagPublicProxy},
+		{anonymousRecord.IsResidentialProxy, FlagResidentialProxy},
+		{anonymousRecord.IsTorExitNode, FlagTorExitNode},
+	}
+	for _, f := range flags {
+		if f.set {
+			record.Flags = append(record.Flags, f.flag)
+		}
+	}
+	return record, nil
+}
+
+// TorExitListProvider 基于本地Tor出口节点列表的数据源
+//
+// 列表文件每行一个IP，也兼容Tor Project发布的exit-addresses格式
+// （"ExitAddress <ip> <time>"行），#开头的行为注释。
+type TorExitListProvider struct {
+	path string
+
+	mu    sync.RWMutex
+	exits map[netip.Addr]struct{}
+}
+
+// NewTorExitListProvider 创建新的TorExitListProvider实例并加载列表
+func NewTorExitListProvider(path string) (*TorExitListProvider, error) {
+	p := &TorExitListProvider{path: path}
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Name 实现Provider接口
+func (p *TorExitListProvider) Name() string {
+	return NameTorExitList
+}
+
+// Lookup 实现Provider接口
+func (p *TorExitListProvider) Lookup(ip net.IP) (*Record, error) {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return nil, nil
+	}
+
+	p.mu.RLock()
+	_, found := p.exits[addr.Unmap()]
+	p.mu.RUnlock()
+	if !found {
+		return nil, nil
+	}
+
+	return &Record{
+		Flags: []string{FlagAnonymous, FlagTorExitNode},
+	}, nil
+}
+
+// Reload 重新加载出口节点列表
+func (p *TorExitListProvider) Reload() error {
+	f, err := os.Open(p.path)
+	if err != nil {
+		return fmt.Errorf("打开Tor出口节点列表失败: %v", err)
+	}
+	defer f.Close()
+
+	exits := make(map[netip.Addr]struct{})
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		value := fields[0]
+		if value == "ExitAddress" && len(fields) > 1 {
+			value = fields[1]
+		}
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			continue
+		}
+		exits[addr.Unmap()] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取Tor出口节点列表失败: %v", err)
+	}
+
+	p.mu.Lock()
+	p.exits = exits
+	p.mu.Unlock()
+
+	logger.Info("加载Tor出口节点列表 %s: %d 个地址", p.path, len(exits))
+	return nil
+}
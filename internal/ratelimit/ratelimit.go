@@ -0,0 +1,131 @@
+// Package ratelimit 按客户端地址限制查询速率
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"ip-geo/internal/config"
+
+	"golang.org/x/time/rate"
+)
+
+// 清理长时间没有请求的客户端
+const (
+	cleanupInterval = time.Minute
+	idleExpiry      = 3 * time.Minute
+)
+
+// Limiter 为每个客户端维护一个令牌桶，未启用时所有方法都直接放行
+type Limiter struct {
+	limit rate.Limit
+	burst int
+
+	mu      sync.Mutex
+	clients map[string]*client
+}
+
+// client 单个客户端的令牌桶
+type client struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+var (
+	instance *Limiter
+	once     sync.Once
+)
+
+// GetInstance 获取按配置创建的共享Limiter，HTTP和whois等入口使用同一个实例共享限额
+func GetInstance() *Limiter {
+	once.Do(func() {
+		cfg := config.GetInstance().RateLimit
+		instance = New(cfg.Rate, cfg.Burst)
+	})
+	return instance
+}
+
+// New 创建每个客户端每秒perSecond次、突发burst次的Limiter，perSecond不大于0时不限制
+func New(perSecond float64, burst int) *Limiter {
+	l := &Limiter{}
+	if perSecond <= 0 {
+		return l
+	}
+	if burst <= 0 {
+		burst = max(int(perSecond), 1)
+	}
+	l.limit = rate.Limit(perSecond)
+	l.burst = burst
+	l.clients = make(map[string]*client)
+	go l.cleanup()
+	return l
+}
+
+// Enabled 判断是否启用了速率限制
+func (l *Limiter) Enabled() bool {
+	return l.clients != nil
+}
+
+// Allow 消耗客户端的一个令牌，令牌不足时返回false，key为空时不限制
+func (l *Limiter) Allow(key string) bool {
+	if !l.Enabled() || key == "" {
+		return true
+	}
+	return l.get(key).Allow()
+}
+
+// Wait 等待客户端有可用的令牌，ctx结束前无法获得令牌时返回错误，key为空时不限制
+func (l *Limiter) Wait(ctx context.Context, key string) error {
+	if !l.Enabled() || key == "" {
+		return nil
+	}
+	return l.get(key).Wait(ctx)
+}
+
+// get 返回客户端的令牌桶，不存在时创建
+func (l *Limiter) get(key string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	c, ok := l.clients[key]
+	if !ok {
+		c = &client{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[key] = c
+	}
+	c.lastSeen = time.Now()
+	return c.limiter
+}
+
+// cleanup 定期删除长时间没有请求的客户端，这些客户端的令牌桶已经装满
+func (l *Limiter) cleanup() {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		l.mu.Lock()
+		for key, c := range l.clients {
+			if time.Since(c.lastSeen) > idleExpiry {
+				delete(l.clients, key)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+// ClientKey 返回连接地址对应的限流键，地址为host:port或IP
+//
+// IPv6地址按/64网段计算，同一网段的地址共享限额。不是IP的地址(如Unix套接字)返回空字符串，不限制。
+func ClientKey(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ""
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.String()
+	}
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(64, 128)), Mask: net.CIDRMask(64, 128)}).String()
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"ip-geo/internal/config"
+)
+
+// 监听的网络类型
+const (
+	NetworkTCP     = "tcp"
+	NetworkUnix    = "unix"
+	NetworkSystemd = "systemd"
+)
+
+// sdListenFDsStart systemd传入的第一个文件描述符
+const sdListenFDsStart = 3
+
+// Listen 根据配置创建监听
+func Listen(l *config.ListenerConfig) (net.Listener, error) {
+	switch l.Network {
+	case "", NetworkTCP:
+		return net.Listen("tcp", l.Addr)
+	case NetworkUnix:
+		return listenUnix(l.Addr, l.Mode)
+	case NetworkSystemd:
+		return systemdListener(l.Addr)
+	}
+	return nil, fmt.Errorf("不支持的网络类型: %s", l.Network)
+}
+
+// listenUnix 监听unix套接字，删除上次运行遗留的套接字文件，并按mode设置文件权限
+func listenUnix(path, mode string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s 已存在且不是套接字文件", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("删除旧的套接字文件失败: %v", err)
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if mode != "" {
+		perm, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("无效的套接字文件权限: %s", mode)
+		}
+		if err := os.Chmod(path, os.FileMode(perm)); err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("设置套接字文件权限失败: %v", err)
+		}
+	}
+	return ln, nil
+}
+
+// systemdSockets systemd套接字激活传入的监听
+var systemdSockets struct {
+	once  sync.Once
+	mu    sync.Mutex
+	files []*os.File
+	names []string
+	used  []bool
+}
+
+// loadSystemdSockets 读取LISTEN_PID、LISTEN_FDS和LISTEN_FDNAMES，读取后清除环境变量，避免子进程误用
+func loadSystemdSockets() {
+	s := &systemdSockets
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	for i := 0; i < count; i++ {
+		name := ""
+		if i < len(names) {
+			name = names[i]
+		}
+		s.files = append(s.files, os.NewFile(uintptr(sdListenFDsStart+i), name))
+		s.names = append(s.names, name)
+	}
+	s.used = make([]bool, count)
+}
+
+// systemdListener 返回systemd传入的监听，name为空时使用第一个未使用的套接字
+func systemdListener(name string) (net.Listener, error) {
+	s := &systemdSockets
+	s.once.Do(loadSystemdSockets)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.files) == 0 {
+		return nil, fmt.Errorf("没有通过systemd套接字激活传入监听")
+	}
+	for i, f := range s.files {
+		if s.used[i] || (name != "" && s.names[i] != name) {
+			continue
+		}
+		s.used[i] = true
+		ln, err := net.FileListener(f)
+		if err != nil {
+			return nil, fmt.Errorf("使用systemd传入的套接字 %d 失败: %v", sdListenFDsStart+i, err)
+		}
+		f.Close()
+		return ln, nil
+	}
+	if name == "" {
+		return nil, fmt.Errorf("systemd传入的套接字已全部使用")
+	}
+	return nil, fmt.Errorf("systemd没有传入名称为 %s 的套接字", name)
+}
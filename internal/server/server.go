@@ -0,0 +1,152 @@
+// Package server 根据配置创建和启动HTTP服务器
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"ip-geo/internal/config"
+	"ip-geo/internal/logger"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// New 根据监听配置创建HTTP服务器
+//
+// 启用TLS时通过ALPN协商HTTP/2，未启用TLS时可以通过h2c支持明文HTTP/2。
+func New(l *config.ListenerConfig, handler http.Handler) (*http.Server, error) {
+	srv := &http.Server{
+		Addr:    l.Addr,
+		Handler: handler,
+	}
+	if l.DisableHTTP2 {
+		// TLSNextProto不为nil时net/http不启用HTTP/2
+		srv.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+	}
+
+	if l.TLS.Enabled() {
+		nextProtos := []string{"h2", "http/1.1"}
+		if l.DisableHTTP2 {
+			nextProtos = []string{"http/1.1"}
+		}
+		tlsConfig, err := NewTLSConfig(&l.TLS, nextProtos)
+		if err != nil {
+			return nil, err
+		}
+		srv.TLSConfig = tlsConfig
+	} else if l.H2C && !l.DisableHTTP2 {
+		srv.Handler = h2c.NewHandler(handler, &http2.Server{})
+	}
+	return srv, nil
+}
+
+// ListenAndServe 按配置启动所有监听，router根据监听的路由组创建处理器
+//
+// 所有监听创建成功后才开始处理请求，任一监听停止时返回错误。
+// 启用TLS并配置了重定向地址的监听同时启动HTTP重定向服务。
+func ListenAndServe(listeners []config.ListenerConfig, router func(routes []string) (http.Handler, error)) error {
+	type listener struct {
+		cfg *config.ListenerConfig
+		srv *http.Server
+		ln  net.Listener
+	}
+
+	var started []listener
+	closeAll := func() {
+		for _, l := range started {
+			l.ln.Close()
+		}
+	}
+	for i := range listeners {
+		l := &listeners[i]
+		if l.Name == "" {
+			l.Name = fmt.Sprintf("listener-%d", i+1)
+		}
+		if l.Network == "" {
+			l.Network = NetworkTCP
+		}
+
+		handler, err := router(l.Routes)
+		if err != nil {
+			closeAll()
+			return fmt.Errorf("监听 %s: %v", l.Name, err)
+		}
+		srv, err := New(l, handler)
+		if err != nil {
+			closeAll()
+			return fmt.Errorf("监听 %s: %v", l.Name, err)
+		}
+		ln, err := Listen(l)
+		if err != nil {
+			closeAll()
+			return fmt.Errorf("监听 %s: %v", l.Name, err)
+		}
+		started = append(started, listener{cfg: l, srv: srv, ln: ln})
+	}
+
+	errChan := make(chan error, len(started))
+	for _, l := range started {
+		go func() {
+			errChan <- serve(l.cfg, l.srv, l.ln)
+		}()
+	}
+	return <-errChan
+}
+
+// serve 在监听上处理请求
+func serve(l *config.ListenerConfig, srv *http.Server, ln net.Listener) error {
+	routes := l.Routes
+	if len(routes) == 0 {
+		routes = []string{"all"}
+	}
+
+	if !l.TLS.Enabled() {
+		logger.Info("监听 %s 启动在 %s %s，路由: %v", l.Name, l.Network, ln.Addr(), routes)
+		return fmt.Errorf("监听 %s 停止: %v", l.Name, srv.Serve(ln))
+	}
+
+	if l.TLS.RedirectAddr != "" {
+		go func() {
+			logger.Info("HTTP重定向服务启动在 %s", l.TLS.RedirectAddr)
+			if err := http.ListenAndServe(l.TLS.RedirectAddr, redirectHandler(ln.Addr().String())); err != nil {
+				logger.Error("HTTP重定向服务启动失败: %v", err)
+			}
+		}()
+	}
+
+	mode := "TLS"
+	if l.TLS.ClientCAFile != "" {
+		mode = "双向TLS"
+	}
+	logger.Info("监听 %s 启动在 %s %s (%s)，路由: %v", l.Name, l.Network, ln.Addr(), mode, routes)
+	return fmt.Errorf("监听 %s 停止: %v", l.Name, srv.ServeTLS(ln, "", ""))
+}
+
+// redirectHandler 将HTTP请求重定向到HTTPS，tlsAddr的端口不是443时保留端口
+func redirectHandler(tlsAddr string) http.Handler {
+	_, port, _ := net.SplitHostPort(tlsAddr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		} else {
+			host = strings.Trim(host, "[]")
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+
+		// GET和HEAD以外的请求使用308，保留请求方法和请求体
+		code := http.StatusMovedPermanently
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			code = http.StatusPermanentRedirect
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), code)
+	})
+}
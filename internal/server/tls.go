@@ -0,0 +1,169 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"ip-geo/internal/config"
+	"ip-geo/internal/logger"
+)
+
+// defaultReloadInterval 默认检查证书文件是否变化的间隔
+const defaultReloadInterval = time.Minute
+
+// certReloader 在证书文件变化后重新加载证书和客户端CA
+//
+// 握手时距离上次检查超过interval才检查文件的修改时间，不需要后台任务，
+// 证书续期后无需重启服务。重新加载失败时继续使用原有的证书。
+type certReloader struct {
+	certFile, keyFile, caFile string
+	interval                  time.Duration
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  [3]time.Time
+	checked   time.Time
+}
+
+// newCertReloader 创建证书加载器并加载证书
+func newCertReloader(cfg *config.TLSConfig) (*certReloader, error) {
+	interval := defaultReloadInterval
+	if cfg.ReloadInterval > 0 {
+		interval = time.Duration(cfg.ReloadInterval) * time.Second
+	}
+	r := &certReloader{
+		certFile: cfg.CertFile,
+		keyFile:  cfg.KeyFile,
+		caFile:   cfg.ClientCAFile,
+		interval: interval,
+	}
+	if err := r.load(r.stat()); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// stat 返回证书、私钥和CA文件的修改时间，文件不存在时为零值
+func (r *certReloader) stat() [3]time.Time {
+	var modTimes [3]time.Time
+	for i, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file == "" {
+			continue
+		}
+		if info, err := os.Stat(file); err == nil {
+			modTimes[i] = info.ModTime()
+		}
+	}
+	return modTimes
+}
+
+// load 读取证书和客户端CA
+func (r *certReloader) load(modTimes [3]time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("加载证书失败: %v", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.caFile != "" {
+		data, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("读取客户端CA失败: %v", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return fmt.Errorf("客户端CA文件 %s 中没有有效的证书", r.caFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	r.checked = time.Now()
+	return nil
+}
+
+// maybeReload 距离上次检查超过间隔时检查文件，发生变化则重新加载
+func (r *certReloader) maybeReload() {
+	r.mu.RLock()
+	due := time.Since(r.checked) >= r.interval
+	r.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	modTimes := r.stat()
+	r.mu.Lock()
+	r.checked = time.Now()
+	changed := modTimes != r.modTimes
+	r.mu.Unlock()
+	if !changed {
+		return
+	}
+
+	if err := r.load(modTimes); err != nil {
+		logger.Error("重新加载证书失败，继续使用原有证书: %v", err)
+		return
+	}
+	logger.Info("证书已重新加载: %s", r.certFile)
+}
+
+// tlsConfig 返回握手时使用的TLS配置，每次握手前检查证书是否需要重新加载
+func (r *certReloader) tlsConfig(base *tls.Config) *tls.Config {
+	cfg := base.Clone()
+	cfg.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		return r.cert, nil
+	}
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.maybeReload()
+
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		c := base.Clone()
+		c.Certificates = []tls.Certificate{*r.cert}
+		c.ClientCAs = r.clientCAs
+		return c, nil
+	}
+	return cfg
+}
+
+// NewTLSConfig 根据配置创建TLS配置，证书文件变化时自动重新加载
+func NewTLSConfig(cfg *config.TLSConfig, nextProtos []string) (*tls.Config, error) {
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: nextProtos,
+	}
+	switch cfg.MinVersion {
+	case "", "1.2":
+	case "1.3":
+		base.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("不支持的TLS版本: %s", cfg.MinVersion)
+	}
+
+	if cfg.ClientCAFile != "" {
+		switch cfg.ClientAuth {
+		case "", "require":
+			base.ClientAuth = tls.RequireAndVerifyClientCert
+		case "optional":
+			base.ClientAuth = tls.VerifyClientCertIfGiven
+		default:
+			return nil, fmt.Errorf("无效的客户端证书要求: %s", cfg.ClientAuth)
+		}
+	}
+
+	r, err := newCertReloader(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return r.tlsConfig(base), nil
+}
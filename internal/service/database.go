@@ -0,0 +1,339 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"ip-geo/internal/api/response"
+	"ip-geo/internal/config"
+	"ip-geo/internal/database"
+	"ip-geo/internal/downloader"
+	"ip-geo/internal/logger"
+	"ip-geo/internal/provider"
+	"ip-geo/internal/snapshot"
+)
+
+// checksumCache 缓存文件的SHA-256，文件大小和修改时间不变时不重新计算
+var checksumCache sync.Map
+
+// checksumKey 文件校验和缓存的键
+type checksumKey struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// DatabaseStatuses 返回所有已配置数据库的状态
+func (s *IPService) DatabaseStatuses() []*response.DatabaseStatus {
+	names := config.GetInstance().DatabaseNames()
+	statuses := make([]*response.DatabaseStatus, 0, len(names))
+	for _, name := range names {
+		status, err := s.DatabaseStatus(name)
+		if err != nil {
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// DatabaseStatus 返回指定数据库的状态
+func (s *IPService) DatabaseStatus(name string) (*response.DatabaseStatus, error) {
+	d, ok := config.GetInstance().Database(name)
+	if !ok {
+		return nil, ErrUnknownDatabase
+	}
+
+	path := d.Path
+	status := &response.DatabaseStatus{
+		Name:     name,
+		Role:     d.Role,
+		Path:     path,
+		Required: d.Required,
+		LoadMode: database.LoadMode(d),
+	}
+	if url, ok := downloader.Source(path); ok {
+		status.SourceURL = url
+	}
+
+	// 受版本管理的数据库从版本清单读取下载信息和校验和
+	var current *snapshot.Version
+	if m, err := downloader.Versions(path).Manifest(); err == nil {
+		current = m.CurrentVersion()
+		status.Versions = len(m.Versions)
+	}
+	if current != nil {
+		status.Version = current.ID
+		status.SHA256 = current.SHA256
+		status.DownloadedAt = &current.DownloadedAt
+		if current.SourceURL != "" {
+			status.SourceURL = current.SourceURL
+		}
+	}
+
+	if reader := s.db.Reader(name); reader != nil {
+		status.Loaded = true
+		status.DatabaseType = reader.Metadata.DatabaseType
+		status.BuildEpoch = reader.Metadata.BuildEpoch
+		status.BuildTime = time.Unix(int64(reader.Metadata.BuildEpoch), 0).UTC()
+		status.Languages = reader.Metadata.Languages
+	}
+	if usage, ok := s.db.Usage(name); ok {
+		status.Memory = usage.Bytes
+	}
+
+	// 内嵌的数据库没有对应的文件
+	if status.LoadMode == database.LoadModeEmbed {
+		status.Size = status.Memory
+		return status, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		status.Error = err.Error()
+		return status, nil
+	}
+	status.Size = info.Size()
+	if current == nil {
+		modTime := info.ModTime()
+		status.DownloadedAt = &modTime
+		if status.SHA256, err = fileChecksum(path, info); err != nil {
+			status.Error = err.Error()
+		}
+	}
+	return status, nil
+}
+
+// RefreshDatabase 重新下载数据库并替换正在使用的数据库
+//
+// 没有配置下载地址时只从磁盘重新加载，适用于文件已被外部更新的情况。
+func (s *IPService) RefreshDatabase(name string) error {
+	path, ok := config.GetInstance().DatabasePath(name)
+	if !ok {
+		return ErrUnknownDatabase
+	}
+
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	if _, ok := downloader.Source(path); ok {
+		if err := downloader.Download(path); err != nil {
+			return err
+		}
+	}
+	return s.reloadDatabase(name, path)
+}
+
+// RollbackDatabase 切换到当前版本之前的一个版本并重新加载
+func (s *IPService) RollbackDatabase(name string) error {
+	return s.switchDatabase(name, func(store *snapshot.Store) (*snapshot.Version, error) {
+		return store.Rollback()
+	})
+}
+
+// ActivateDatabaseVersion 切换到指定版本并重新加载
+func (s *IPService) ActivateDatabaseVersion(name, id string) error {
+	return s.switchDatabase(name, func(store *snapshot.Store) (*snapshot.Version, error) {
+		return store.Activate(id)
+	})
+}
+
+// DatabaseVersions 返回数据库的版本清单
+func (s *IPService) DatabaseVersions(name string) (*snapshot.Manifest, error) {
+	path, ok := config.GetInstance().DatabasePath(name)
+	if !ok {
+		return nil, ErrUnknownDatabase
+	}
+	return downloader.Versions(path).Manifest()
+}
+
+// switchDatabase 切换数据库版本并重新加载
+func (s *IPService) switchDatabase(name string, switchVersion func(*snapshot.Store) (*snapshot.Version, error)) error {
+	path, ok := config.GetInstance().DatabasePath(name)
+	if !ok {
+		return ErrUnknownDatabase
+	}
+
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	v, err := switchVersion(downloader.Versions(path))
+	if err != nil {
+		return fmt.Errorf("切换数据库 %s 的版本失败: %v", name, err)
+	}
+	logger.Info("数据库 %s 已切换到版本 %s", name, v.ID)
+	return s.reloadDatabase(name, path)
+}
+
+// reloadChangedDatabases 重新加载版本已在磁盘上切换的数据库，如通过dbctl回滚后
+func (s *IPService) reloadChangedDatabases() error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	var errs []error
+	for _, d := range config.GetInstance().EnabledDatabases() {
+		if s.currentVersion(d.Path) == s.loadedVersions[d.Name] {
+			continue
+		}
+		if err := s.reloadDatabase(d.Name, d.Path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// currentVersion 返回磁盘上的当前版本，数据库不受版本管理时为空
+func (s *IPService) currentVersion(path string) string {
+	m, err := downloader.Versions(path).Manifest()
+	if err != nil {
+		return ""
+	}
+	return m.Current
+}
+
+// reloadDatabase 打开数据库文件并替换正在使用的数据库，进行中的查询不受影响
+func (s *IPService) reloadDatabase(name, path string) error {
+	p, ok := s.providers[name].(*provider.MMDBProvider)
+	if !ok {
+		return fmt.Errorf("数据源 %s 创建失败，需要重启服务", name)
+	}
+
+	d, ok := config.GetInstance().Database(name)
+	if !ok {
+		return ErrUnknownDatabase
+	}
+	reader, usage, err := database.Open(d)
+	if err != nil {
+		return fmt.Errorf("打开数据库 %s 失败: %v", path, err)
+	}
+
+	old, err := p.Replace(reader, resolveSchema(name, reader))
+	if err != nil {
+		reader.Close()
+		return err
+	}
+	if _, err := s.db.Swap(name, reader, usage); err != nil {
+		logger.Warn("更新数据库管理器失败: %v", err)
+	}
+	if old != nil {
+		if err := old.Close(); err != nil {
+			logger.Warn("关闭旧数据库失败: %v", err)
+		}
+	}
+
+	s.loadedVersions[name] = s.currentVersion(path)
+	logger.Info("数据库 %s 已重新加载: %s (%s)，加载方式 %s，占用 %d 字节", name, path, reader.Metadata.DatabaseType, usage.Mode, usage.Bytes)
+	return nil
+}
+
+// fileChecksum 计算文件的SHA-256
+func fileChecksum(path string, info os.FileInfo) (string, error) {
+	key := checksumKey{path: path, size: info.Size(), modTime: info.ModTime()}
+	if sum, ok := checksumCache.Load(key); ok {
+		return sum.(string), nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	checksumCache.Store(key, sum)
+	return sum, nil
+}
+
+// UnavailableSources 返回已配置但数据库不可用的数据源
+func (s *IPService) UnavailableSources() []string {
+	var names []string
+	for _, name := range config.GetInstance().DatabaseNames() {
+		if p, ok := s.providers[name].(*provider.MMDBProvider); !ok || !p.Loaded() {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Ready 判断服务是否可以处理查询，所有必需的数据库都需要可用，
+// 并且至少需要一个地理位置或ASN数据源可用，内嵌的国家级数据库也可以满足后者
+func (s *IPService) Ready() bool {
+	_, ready := s.providers[provider.NameFallback]
+	for _, d := range config.GetInstance().EnabledDatabases() {
+		p, ok := s.providers[d.Name].(*provider.MMDBProvider)
+		loaded := ok && p.Loaded()
+		if d.Required && !loaded {
+			return false
+		}
+		switch d.Role {
+		case provider.NameASN, provider.NameCity, provider.NameGeoCN:
+			ready = ready || loaded
+		}
+	}
+	return ready
+}
+
+// 后台重试不可用数据库的间隔
+const (
+	retryInitialDelay = 30 * time.Second
+	retryMaxDelay     = 30 * time.Minute
+)
+
+// RetryUnavailable 在后台重试下载和加载不可用的数据库，全部可用后返回
+//
+// 重试间隔从retryInitialDelay开始加倍，最长为retryMaxDelay。
+func (s *IPService) RetryUnavailable() {
+	delay := retryInitialDelay
+	for {
+		names := s.UnavailableSources()
+		if len(names) == 0 {
+			return
+		}
+
+		logger.Info("%v 后重试加载不可用的数据库: %v", delay, names)
+		time.Sleep(delay)
+		for _, name := range names {
+			if err := s.loadUnavailable(name); err != nil {
+				logger.Warn("加载数据库 %s 失败: %v", name, err)
+			}
+		}
+		delay = min(delay*2, retryMaxDelay)
+	}
+}
+
+// loadUnavailable 加载不可用的数据库，文件不存在时先下载
+func (s *IPService) loadUnavailable(name string) error {
+	path, ok := config.GetInstance().DatabasePath(name)
+	if !ok {
+		return ErrUnknownDatabase
+	}
+
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	// 等待期间可能已通过管理接口加载
+	if p, ok := s.providers[name].(*provider.MMDBProvider); ok && p.Loaded() {
+		return nil
+	}
+
+	d, _ := config.GetInstance().Database(name)
+	if _, err := os.Stat(path); os.IsNotExist(err) && database.LoadMode(d) != database.LoadModeEmbed {
+		if _, ok := downloader.Source(path); !ok {
+			return fmt.Errorf("文件 %s 不存在且没有下载地址", path)
+		}
+		if err := downloader.Download(path); err != nil {
+			return err
+		}
+	}
+	return s.reloadDatabase(name, path)
+}
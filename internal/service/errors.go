@@ -5,4 +5,6 @@ import "errors"
 var (
 	// ErrInvalidIP 表示无效的IP地址
 	ErrInvalidIP = errors.New("无效的IP地址")
+	// ErrUnknownDatabase 表示未配置的数据库
+	ErrUnknownDatabase = errors.New("未知的数据库")
 )
@@ -1,287 +1,249 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"net"
 	"strings"
+	"sync"
 
 	"ip-geo/internal/api/response"
+	"ip-geo/internal/config"
 	"ip-geo/internal/database"
+	"ip-geo/internal/fallback"
 	"ip-geo/internal/logger"
+	"ip-geo/internal/override"
+	"ip-geo/internal/provider"
 	"ip-geo/pkg/asn"
+
+	"github.com/oschwald/maxminddb-golang"
 )
 
 // IPService 处理IP查询相关的业务逻辑
 type IPService struct {
-	db *database.MMDBManager
+	db        *database.MMDBManager
+	providers map[string]provider.Provider
+	policy    MergePolicy
+
+	// overrideSources 优先于合并策略的覆盖数据源
+	overrideSources []string
+	// securitySources 汇总安全标记的数据源
+	securitySources []string
+	// hostingASNs 托管服务商ASN集合
+	hostingASNs map[uint]struct{}
+
+	// reloadMu 保证同一时间只有一个数据库更新操作
+	reloadMu sync.Mutex
+	// loadedVersions 每个数据库已加载的版本
+	loadedVersions map[string]string
 }
 
-// NewIPService 创建新的IPService实例
-func NewIPService() *IPService {
-	return &IPService{
-		db: database.GetInstance(),
-	}
+var (
+	instance *IPService
+	once     sync.Once
+)
+
+// GetInstance 获取共享的IPService实例
+func GetInstance() *IPService {
+	once.Do(func() {
+		instance = NewIPService()
+	})
+	return instance
 }
 
-// LookupIP 查询IP信息
-func (s *IPService) LookupIP(ip string) (*response.IPResponse, error) {
-	logger.Info("开始查询IP: %s", ip)
-	resp := &response.IPResponse{IP: ip}
+// NewIPService 创建新的IPService实例
+func NewIPService() *IPService {
+	db := database.GetInstance()
+	cfg := config.GetInstance()
+
+	databases := cfg.EnabledDatabases()
+	providers := make([]provider.Provider, 0, len(databases))
+	for _, d := range databases {
+		if _, ok := roleSchemas[d.Role]; !ok {
+			logger.Error("数据库 %s 的角色 %s 无效", d.Name, d.Role)
+			continue
+		}
 
-	// 解析IP地址
-	parsedIP := net.ParseIP(ip)
-	if parsedIP == nil {
-		logger.Warn("无效的IP地址: %s", ip)
-		return nil, ErrInvalidIP
+		// 数据库不可用时创建未加载的数据源，之后在后台重试加载
+		reader := db.Reader(d.Name)
+		schema := resolveSchema(d.Name, reader)
+		var p *provider.MMDBProvider
+		var err error
+		if reader != nil {
+			logger.Debug("数据源 %s 使用记录格式 %s (%s)", d.Name, schema, reader.Metadata.DatabaseType)
+			p, err = provider.New(d.Name, reader, schema)
+		} else {
+			logger.Warn("数据库 %s 不可用: %s", d.Name, d.Path)
+			p, err = provider.NewPending(d.Name, schema)
+		}
+		if err != nil {
+			logger.Error("创建数据源 %s 失败: %v", d.Name, err)
+			continue
+		}
+		providers = append(providers, p)
 	}
 
-	// 设置IP版本
-	if parsedIP.To4() != nil {
-		resp.Version = "IPv4"
-	} else {
-		resp.Version = "IPv6"
-	}
-	logger.Debug("IP版本: %s", resp.Version)
-
-	// 查询ASN信息
-	if err := s.lookupASN(parsedIP, resp); err != nil {
-		logger.Warn("查询ASN信息失败: %v", err)
+	// 使用fallback构建标签编译时，内嵌的国家级数据库在没有其他数据源时提供洲和国家
+	if reader, err := fallback.Open(); err == nil {
+		p, err := provider.New(provider.NameFallback, reader, provider.SchemaGeoIP2City)
+		if err != nil {
+			logger.Error("创建数据源 %s 失败: %v", provider.NameFallback, err)
+		} else {
+			logger.Info("已加载内嵌的国家级数据库 (%s)", reader.Metadata.DatabaseType)
+			providers = append(providers, p)
+		}
+	} else if err != fallback.ErrNotBundled {
+		logger.Error("打开内嵌的国家级数据库失败: %v", err)
 	}
 
-	// 查询地理位置信息
-	// 先尝试从GeoCN数据库获取中国IP信息
-	if err := s.lookupGeoCN(parsedIP, resp); err != nil {
-		logger.Debug("从GeoCN查询失败，尝试使用GeoIP2: %v", err)
-		// 如果GeoCN查询失败，使用GeoIP2数据库
-		if err := s.lookupGeoIP2(parsedIP, resp); err != nil {
-			logger.Warn("GeoIP2查询也失败: %v", err)
+	// 可选的安全检测数据源
+	if cfg.Security.TorExitList != "" {
+		p, err := provider.NewTorExitListProvider(cfg.Security.TorExitList)
+		if err != nil {
+			logger.Warn("加载Tor出口节点列表失败: %v", err)
+		} else {
+			providers = append(providers, p)
 		}
 	}
 
-	// 如果网络信息仍然为空，设置默认网段
-	if resp.Network.CIDR == "" {
-		s.setDefaultNetwork(parsedIP, resp)
+	// 本地覆盖数据，首次加载失败时仍然创建，以便之后重新加载
+	if cfg.OverridesPath != "" {
+		store := override.NewStore(cfg.OverridesPath)
+		if err := store.Reload(); err != nil {
+			logger.Warn("加载覆盖数据失败: %v", err)
+		}
+		providers = append(providers, store)
 	}
 
-	logger.Info("IP查询完成: %s", ip)
-	return resp, nil
-}
-
-// lookupASN 查询ASN信息
-func (s *IPService) lookupASN(ip net.IP, resp *response.IPResponse) error {
-	var asnRecord struct {
-		AutonomousSystemNumber       uint      `maxminddb:"autonomous_system_number"`
-		AutonomousSystemOrganization string    `maxminddb:"autonomous_system_organization"`
-		Network                      net.IPNet `maxminddb:"network"`
+	// 合并策略和安全标记中的角色替换为该角色的数据库，同一角色的多个数据库按配置顺序尝试
+	roles := make(map[string][]string)
+	for _, p := range providers {
+		if d, ok := cfg.Database(p.Name()); ok {
+			roles[d.Role] = append(roles[d.Role], d.Name)
+		}
 	}
-
-	if err := s.db.ASNDB.Lookup(ip, &asnRecord); err != nil {
-		return err
+	s := NewIPServiceWithProviders(providers, NewMergePolicy(cfg.MergePolicy).expandRoles(roles))
+	s.securitySources = expandRoles(DefaultSecuritySources, roles)
+	s.overrideSources = expandRoles(overrideSources, roles)
+	if cfg.Security.HostingASNs != nil {
+		s.hostingASNs = newASNSet(cfg.Security.HostingASNs)
 	}
 
-	resp.ASN.Number = asnRecord.AutonomousSystemNumber
-	resp.ASN.Name = asnRecord.AutonomousSystemOrganization
-	if info, ok := asn.Map[int(asnRecord.AutonomousSystemNumber)]; ok {
-		resp.ASN.Info = info
-		resp.ISP.Type = info
+	for _, d := range databases {
+		s.loadedVersions[d.Name] = s.currentVersion(d.Path)
 	}
-	resp.ISP.Name = asnRecord.AutonomousSystemOrganization
 
-	if asnRecord.Network.IP != nil && asnRecord.Network.Mask != nil {
-		s.setNetworkInfo(resp, asnRecord.Network)
+	// 没有GeoCN数据库时，合并了GeoCN数据的City数据库同时作为geocn数据源，一次查询即可得到两者的数据
+	if len(roles[provider.NameGeoCN]) == 0 {
+		for _, name := range roles[provider.NameCity] {
+			if p, ok := s.providers[name].(*provider.MMDBProvider); ok && p.Schema() == provider.SchemaGeoIP2CityGeoCN {
+				logger.Debug("数据源 %s 使用数据库 %s 中合并的GeoCN数据", provider.NameGeoCN, name)
+				s.providers[provider.NameGeoCN] = p
+				break
+			}
+		}
 	}
-
-	return nil
+	return s
 }
 
-// lookupGeoCN 从GeoCN数据库查询信息
-func (s *IPService) lookupGeoCN(ip net.IP, resp *response.IPResponse) error {
-	var geoCNRecord struct {
-		Province      string `maxminddb:"province"`
-		ProvinceCode  uint64 `maxminddb:"provinceCode"`
-		City          string `maxminddb:"city"`
-		CityCode      uint64 `maxminddb:"cityCode"`
-		Districts     string `maxminddb:"districts"`
-		DistrictsCode uint64 `maxminddb:"districtsCode"`
-		ISP           string `maxminddb:"isp"`
-		Net           string `maxminddb:"net"`
-	}
-
-	if err := s.db.GeoCNDB.Lookup(ip, &geoCNRecord); err != nil {
-		return err
-	}
-
-	// 只有当Province或ISP字段不为空时才认为是有效的中国IP记录
-	if geoCNRecord.Province == "" && geoCNRecord.ISP == "" {
-		return fmt.Errorf("无效的GeoCN记录")
-	}
-
-	// 设置基本信息
-	resp.Location.Country.Code = "CN"
-	resp.Location.Country.Name = "中国"
-	resp.Location.Location.TimeZone = "Asia/Shanghai"
-
-	// 处理地区信息
-	regions := []string{geoCNRecord.Province, geoCNRecord.City, geoCNRecord.Districts}
-	regions = removeEmpty(regions)
-	fullName := strings.Join(regions, "")
-
-	// 设置地区代码
-	var lastCode string
-	if geoCNRecord.Districts != "" {
-		lastCode = fmt.Sprintf("%d", geoCNRecord.DistrictsCode)
-	} else if geoCNRecord.City != "" {
-		lastCode = fmt.Sprintf("%d", geoCNRecord.CityCode)
-	} else if geoCNRecord.Province != "" {
-		lastCode = fmt.Sprintf("%d", geoCNRecord.ProvinceCode)
-	}
-
-	resp.Location.Region = response.Region{
-		Code: lastCode,
-		Name: fullName,
-	}
+// roleSchemas 数据库角色及其默认记录格式
+//
+// 自定义数据库与覆盖文件一样优先于其他数据源，由合并策略决定。
+var roleSchemas = map[string]string{
+	provider.NameASN:         provider.SchemaGeoIP2ASN,
+	provider.NameCity:        provider.SchemaGeoIP2City,
+	provider.NameGeoCN:       provider.SchemaGeoCN,
+	provider.NameAnonymousIP: provider.SchemaGeoIP2AnonymousIP,
+	provider.NameCustom:      provider.SchemaCustom,
+}
 
-	// 设置ISP和网络信息
-	if geoCNRecord.ISP != "" {
-		resp.ISP.Name = geoCNRecord.ISP
-		resp.ASN.Info = geoCNRecord.ISP
-	}
-	if geoCNRecord.Net != "" {
-		resp.Network.Type = geoCNRecord.Net
+// resolveSchema 返回数据源的记录格式，优先使用配置的格式，其次根据数据库类型识别，
+// 无法识别或数据库未打开时使用角色的默认格式
+func resolveSchema(name string, reader *maxminddb.Reader) string {
+	d, ok := config.GetInstance().Database(name)
+	if !ok {
+		return ""
 	}
-
-	// 从GeoIP2-City数据库补充经纬度等信息
-	var cityRecord struct {
-		Location struct {
-			AccuracyRadius uint16  `maxminddb:"accuracy_radius"`
-			Latitude       float64 `maxminddb:"latitude"`
-			Longitude      float64 `maxminddb:"longitude"`
-		} `maxminddb:"location"`
-		Continent struct {
-			Code  string            `maxminddb:"code"`
-			Names map[string]string `maxminddb:"names"`
-		} `maxminddb:"continent"`
+	if d.Schema != "" {
+		return d.Schema
 	}
-
-	if err := s.db.CityDB.Lookup(ip, &cityRecord); err == nil {
-		// 补充位置信息
-		resp.Location.Location.Latitude = cityRecord.Location.Latitude
-		resp.Location.Location.Longitude = cityRecord.Location.Longitude
-		resp.Location.Location.AccuracyRadius = cityRecord.Location.AccuracyRadius
-
-		// 补充大洲信息
-		if resp.Location.Continent.Code == "" {
-			resp.Location.Continent.Code = cityRecord.Continent.Code
-			resp.Location.Continent.Name = getLocalizedName(cityRecord.Continent.Names, "zh-CN", "en")
+	if reader != nil {
+		if detected, ok := provider.DetectSchema(reader.Metadata.DatabaseType); ok {
+			return detected
 		}
-
-		logger.Debug("从GeoIP2补充位置信息 - 经度: %f, 纬度: %f, 精度: %d",
-			cityRecord.Location.Longitude,
-			cityRecord.Location.Latitude,
-			cityRecord.Location.AccuracyRadius)
-	} else {
-		logger.Debug("从GeoIP2补充位置信息失败: %v", err)
 	}
-
-	return nil
+	return roleSchemas[d.Role]
 }
 
-// lookupGeoIP2 从GeoIP2数据库查询信息
-func (s *IPService) lookupGeoIP2(ip net.IP, resp *response.IPResponse) error {
-	var record struct {
-		Continent struct {
-			Code      string            `maxminddb:"code"`
-			GeonameID uint32            `maxminddb:"geoname_id"`
-			Names     map[string]string `maxminddb:"names"`
-		} `maxminddb:"continent"`
-		Country struct {
-			GeonameID uint32            `maxminddb:"geoname_id"`
-			ISOCode   string            `maxminddb:"iso_code"`
-			Names     map[string]string `maxminddb:"names"`
-		} `maxminddb:"country"`
-		RegisteredCountry struct {
-			GeonameID uint32            `maxminddb:"geoname_id"`
-			ISOCode   string            `maxminddb:"iso_code"`
-			Names     map[string]string `maxminddb:"names"`
-		} `maxminddb:"registered_country"`
-		City struct {
-			GeonameID uint32            `maxminddb:"geoname_id"`
-			Names     map[string]string `maxminddb:"names"`
-		} `maxminddb:"city"`
-		Subdivisions []struct {
-			GeonameID uint32            `maxminddb:"geoname_id"`
-			ISOCode   string            `maxminddb:"iso_code"`
-			Names     map[string]string `maxminddb:"names"`
-		} `maxminddb:"subdivisions"`
-		Location struct {
-			AccuracyRadius uint16  `maxminddb:"accuracy_radius"`
-			Latitude       float64 `maxminddb:"latitude"`
-			Longitude      float64 `maxminddb:"longitude"`
-			TimeZone       string  `maxminddb:"time_zone"`
-		} `maxminddb:"location"`
-		Traits struct {
-			IsAnycast bool `maxminddb:"is_anycast"`
-		} `maxminddb:"traits"`
-		Network net.IPNet `maxminddb:"network"`
-	}
-
-	if err := s.db.CityDB.Lookup(ip, &record); err != nil {
-		return err
-	}
-
-	// 设置大洲信息
-	if record.Continent.Code != "" {
-		resp.Location.Continent.Code = record.Continent.Code
-		resp.Location.Continent.Name = getLocalizedName(record.Continent.Names, "zh-CN", "en")
-	}
-
-	// 对于Anycast IP，使用registered_country的信息
-	if record.Traits.IsAnycast {
-		logger.Debug("检测到Anycast IP: %s", ip)
-		resp.Location.Country.Code = record.RegisteredCountry.ISOCode
-		resp.Location.Country.Name = getLocalizedName(record.RegisteredCountry.Names, "zh-CN", "en")
+// NewIPServiceWithProviders 使用指定的数据源和合并策略创建IPService实例
+func NewIPServiceWithProviders(providers []provider.Provider, policy MergePolicy) *IPService {
+	s := &IPService{
+		db:              database.GetInstance(),
+		providers:       make(map[string]provider.Provider, len(providers)),
+		policy:          policy,
+		overrideSources: overrideSources,
+		securitySources: DefaultSecuritySources,
+		hostingASNs:     newASNSet(asn.HostingASNs),
+		loadedVersions:  make(map[string]string),
+	}
+	for _, p := range providers {
+		s.providers[p.Name()] = p
+	}
+	return s
+}
 
-		// Anycast IP通常不设置具体的地区和城市信息
-		return nil
+// Reload 重新加载支持运行时更新的数据源，如覆盖数据和Tor出口节点列表，
+// 以及版本已在磁盘上切换的数据库
+func (s *IPService) Reload() error {
+	var errs []error
+	if err := s.reloadChangedDatabases(); err != nil {
+		errs = append(errs, err)
+	}
+	for name, p := range s.providers {
+		reloader, ok := p.(provider.Reloader)
+		if !ok {
+			continue
+		}
+		logger.Info("重新加载数据源: %s", name)
+		if err := reloader.Reload(); err != nil {
+			errs = append(errs, fmt.Errorf("重新加载数据源 %s 失败: %v", name, err))
+		}
 	}
+	return errors.Join(errs...)
+}
 
-	// 设置国家信息（非Anycast IP）
-	if record.Country.ISOCode != "" {
-		resp.Location.Country.Code = record.Country.ISOCode
-		resp.Location.Country.Name = getLocalizedName(record.Country.Names, "zh-CN", "en")
-	}
+// LookupIP 查询IP信息
+func (s *IPService) LookupIP(ip string) (*response.IPResponse, error) {
+	logger.Info("开始查询IP: %s", ip)
+	resp := &response.IPResponse{IP: ip}
 
-	// 设置地区信息
-	if len(record.Subdivisions) > 0 {
-		subdivision := record.Subdivisions[0]
-		resp.Location.Region = response.Region{
-			Code: subdivision.ISOCode,
-			Name: getLocalizedName(subdivision.Names, "zh-CN", "en"),
-		}
+	// 解析IP地址
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		logger.Warn("无效的IP地址: %s", ip)
+		return nil, ErrInvalidIP
 	}
 
-	// 设置城市信息
-	if cityName := getLocalizedName(record.City.Names, "zh-CN", "en"); cityName != "" {
-		resp.Location.City = response.City{
-			Name: cityName,
-		}
+	// 设置IP版本
+	if parsedIP.To4() != nil {
+		resp.Version = "IPv4"
+	} else {
+		resp.Version = "IPv6"
 	}
+	logger.Debug("IP版本: %s", resp.Version)
 
-	// 设置位置信息
-	if record.Location.TimeZone != "" {
-		resp.Location.Location.Latitude = record.Location.Latitude
-		resp.Location.Location.Longitude = record.Location.Longitude
-		resp.Location.Location.AccuracyRadius = record.Location.AccuracyRadius
-		resp.Location.Location.TimeZone = record.Location.TimeZone
-	}
+	// 按合并策略从各数据源获取信息
+	s.merge(parsedIP, resp)
+	resp.SourcesUnavailable = s.UnavailableSources()
 
-	// 如果网络信息为空，使用GeoIP2的网络信息
-	if resp.Network.CIDR == "" && record.Network.IP != nil && record.Network.Mask != nil {
-		s.setNetworkInfo(resp, record.Network)
+	// 如果网络信息仍然为空，设置默认网段
+	if resp.Network.CIDR == "" {
+		s.setDefaultNetwork(parsedIP, resp)
 	}
 
-	return nil
+	logger.Info("IP查询完成: %s", ip)
+	return resp, nil
 }
 
 // setNetworkInfo 设置网络信息
@@ -333,17 +295,6 @@ func GetRealIP(headers map[string]string, remoteAddr string) string {
 	return ip
 }
 
-// 获取本地化名称
-func getLocalizedName(names map[string]string, primaryLang, fallbackLang string) string {
-	if name, ok := names[primaryLang]; ok {
-		return name
-	}
-	if name, ok := names[fallbackLang]; ok {
-		return name
-	}
-	return ""
-}
-
 // 计算网段的起始和结束IP
 func calculateNetworkRange(network net.IPNet) (net.IP, net.IP) {
 	// 计算起始IP
@@ -370,17 +321,6 @@ func calculateTotalIPs(network net.IPNet) uint64 {
 	return uint64(math.Pow(2, float64(128-prefixLen)))
 }
 
-// 辅助函数：移除空字符串
-func removeEmpty(arr []string) []string {
-	result := make([]string, 0)
-	for _, str := range arr {
-		if str != "" {
-			result = append(result, str)
-		}
-	}
-	return result
-}
-
 // 辅助函数：移除重复项
 func removeDuplicates(arr []string) []string {
 	seen := make(map[string]bool)
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"net"
+
+	"ip-geo/internal/api/response"
+	"ip-geo/internal/logger"
+	"ip-geo/internal/provider"
+)
+
+// MergePolicy 每个字段按顺序尝试的数据源名称
+type MergePolicy map[provider.Field][]string
+
+// DefaultMergePolicy 默认合并策略：中国IP优先使用GeoCN，其余使用GeoIP2，
+// 都没有时洲和国家使用内嵌的国家级数据库
+//
+// GeoCN有记录时国家、地区、城市和时区都以GeoCN为准，不从GeoIP2补充。
+// 网段使用匹配到的数据库网段，都没有时IPv4为所在的/24，IPv6为所在的/64。
+var DefaultMergePolicy = MergePolicy{
+	provider.FieldASN:         {provider.NameASN},
+	provider.FieldASNInfo:     {provider.NameGeoCN, provider.NameASN},
+	provider.FieldContinent:   {provider.NameCity, provider.NameFallback},
+	provider.FieldCountry:     {provider.NameGeoCN, provider.NameCity, provider.NameFallback},
+	provider.FieldRegion:      {provider.NameGeoCN, provider.NameCity},
+	provider.FieldCity:        {provider.NameGeoCN, provider.NameCity},
+	provider.FieldLocation:    {provider.NameCity},
+	provider.FieldTimeZone:    {provider.NameGeoCN, provider.NameCity},
+	provider.FieldISP:         {provider.NameGeoCN, provider.NameASN},
+	provider.FieldISPType:     {provider.NameASN},
+	provider.FieldNetworkType: {provider.NameGeoCN},
+	provider.FieldNetwork:     {provider.NameASN, provider.NameGeoCN, provider.NameCity},
+}
+
+// NewMergePolicy 在默认合并策略的基础上应用配置中的字段策略
+func NewMergePolicy(overrides map[string][]string) MergePolicy {
+	policy := make(MergePolicy, len(DefaultMergePolicy))
+	for field, names := range DefaultMergePolicy {
+		policy[field] = names
+	}
+
+	known := make(map[provider.Field]bool, len(provider.Fields))
+	for _, field := range provider.Fields {
+		known[field] = true
+	}
+
+	for name, names := range overrides {
+		field := provider.Field(name)
+		if !known[field] {
+			logger.Warn("合并策略中存在未知字段: %s", name)
+			continue
+		}
+		policy[field] = names
+	}
+	return policy
+}
+
+// expandRoles 将策略中的角色替换为该角色的数据库
+func (p MergePolicy) expandRoles(roles map[string][]string) MergePolicy {
+	expanded := make(MergePolicy, len(p))
+	for field, names := range p {
+		expanded[field] = expandRoles(names, roles)
+	}
+	return expanded
+}
+
+// expandRoles 将数据源名称列表中的角色替换为该角色的数据库，没有对应数据库的名称保持不变
+func expandRoles(names []string, roles map[string][]string) []string {
+	var expanded []string
+	for _, name := range names {
+		if databases, ok := roles[name]; ok {
+			expanded = append(expanded, databases...)
+		} else {
+			expanded = append(expanded, name)
+		}
+	}
+	return removeDuplicates(expanded)
+}
+
+// overrideSources 覆盖数据源，按顺序取第一个有匹配的记录
+var overrideSources = []string{
+	provider.NameOverride,
+	provider.NameCustom,
+}
+
+// lookupSession 单次查询中按需调用数据源并缓存结果
+type lookupSession struct {
+	ip        net.IP
+	providers map[string]provider.Provider
+	records   map[provider.Provider]*provider.Record
+}
+
+// record 获取指定数据源的查询结果，每个数据源最多查询一次
+//
+// 多个名称指向同一数据源时共用查询结果。
+func (ls *lookupSession) record(name string) *provider.Record {
+	p, ok := ls.providers[name]
+	if !ok {
+		return nil
+	}
+	if record, ok := ls.records[p]; ok {
+		return record
+	}
+
+	record, err := p.Lookup(ls.ip)
+	if err != nil {
+		logger.Warn("从数据源 %s 查询失败: %v", name, err)
+	}
+	ls.records[p] = record
+	return record
+}
+
+// merge 按合并策略将各数据源的结果写入响应
+func (s *IPService) merge(ip net.IP, resp *response.IPResponse) {
+	session := &lookupSession{
+		ip:        ip,
+		providers: s.providers,
+		records:   make(map[provider.Provider]*provider.Record),
+	}
+
+	// 本地覆盖数据优先于所有数据源，覆盖文件优先于编译生成的自定义数据库
+	var override *provider.Record
+	for _, name := range s.overrideSources {
+		if override = session.record(name); override != nil {
+			resp.Tags = override.Tags
+			break
+		}
+	}
+
+	for _, field := range provider.Fields {
+		if override != nil && override.Has(field) {
+			logger.Debug("字段 %s 使用覆盖数据", field)
+			s.applyField(field, override, resp)
+			resp.Overrides = append(resp.Overrides, string(field))
+			continue
+		}
+
+		for _, name := range s.policy[field] {
+			record := session.record(name)
+			if record == nil || !record.Has(field) {
+				continue
+			}
+			logger.Debug("字段 %s 使用数据源 %s", field, name)
+			s.applyField(field, record, resp)
+			if name == provider.NameFallback {
+				resp.Fallback = append(resp.Fallback, string(field))
+			}
+			break
+		}
+	}
+
+	s.applySecurity(session, resp)
+}
+
+// applyField 将记录中的单个字段写入响应
+func (s *IPService) applyField(field provider.Field, record *provider.Record, resp *response.IPResponse) {
+	switch field {
+	case provider.FieldASN:
+		resp.ASN.Number = record.ASNNumber
+		resp.ASN.Name = record.ASNName
+	case provider.FieldASNInfo:
+		resp.ASN.Info = record.ASNInfo
+	case provider.FieldContinent:
+		resp.Location.Continent.Code = record.ContinentCode
+		resp.Location.Continent.Name = record.ContinentName
+	case provider.FieldCountry:
+		resp.Location.Country.Code = record.CountryCode
+		resp.Location.Country.Name = record.CountryName
+	case provider.FieldRegion:
+		resp.Location.Region = response.Region{
+			Code: record.RegionCode,
+			Name: record.RegionName,
+		}
+	case provider.FieldCity:
+		resp.Location.City = response.City{
+			Name: record.CityName,
+		}
+	case provider.FieldLocation:
+		resp.Location.Location.Latitude = record.Latitude
+		resp.Location.Location.Longitude = record.Longitude
+		resp.Location.Location.AccuracyRadius = record.AccuracyRadius
+	case provider.FieldTimeZone:
+		resp.Location.Location.TimeZone = record.TimeZone
+	case provider.FieldISP:
+		resp.ISP.Name = record.ISPName
+	case provider.FieldISPType:
+		resp.ISP.Type = record.ISPType
+	case provider.FieldNetworkType:
+		resp.Network.Type = record.NetworkType
+	case provider.FieldNetwork:
+		s.setNetworkInfo(resp, *record.Network)
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"ip-geo/internal/api/response"
+	"ip-geo/internal/mmdbwriter"
+	"ip-geo/internal/provider"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// testProvider 由networks生成数据库并创建数据源
+func testProvider(t *testing.T, name, schema, databaseType string, networks map[string]map[string]any) provider.Provider {
+	t.Helper()
+	tree := mmdbwriter.New(mmdbwriter.Metadata{DatabaseType: databaseType, Languages: []string{"zh-CN", "en"}})
+	for cidr, value := range networks {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tree.Insert(network, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if _, err := tree.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := maxminddb.FromBytes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := provider.New(name, reader, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// testService 使用GeoCN和GeoIP2 City格式的测试数据库创建IPService
+func testService(t *testing.T) *IPService {
+	t.Helper()
+	geoCN := testProvider(t, provider.NameGeoCN, provider.SchemaGeoCN, "GeoCN", map[string]map[string]any{
+		"114.114.112.0/24": {"province": "江苏省", "provinceCode": uint64(320000), "city": "南京市", "cityCode": uint64(320100), "isp": "电信", "net": "数据中心"},
+		// 只有ISP没有省份的记录
+		"114.114.113.0/24": {"isp": "电信"},
+	})
+	nanjing := map[string]any{
+		"continent":    map[string]any{"code": "AS", "names": map[string]string{"zh-CN": "亚洲"}},
+		"country":      map[string]any{"iso_code": "CN", "names": map[string]string{"zh-CN": "中国"}},
+		"subdivisions": []any{map[string]any{"iso_code": "JS", "names": map[string]string{"zh-CN": "江苏省"}}},
+		"city":         map[string]any{"names": map[string]string{"zh-CN": "南京"}},
+		"location":     map[string]any{"latitude": 32.0617, "longitude": 118.7778, "accuracy_radius": uint16(50), "time_zone": "Asia/Shanghai"},
+	}
+	city := testProvider(t, provider.NameCity, provider.SchemaGeoIP2City, "GeoIP2-City", map[string]map[string]any{
+		"114.114.0.0/16": nanjing,
+		"8.8.8.0/24": {
+			"continent": map[string]any{"code": "NA", "names": map[string]string{"zh-CN": "北美洲"}},
+			"country":   map[string]any{"iso_code": "US", "names": map[string]string{"zh-CN": "美国"}},
+			"city":      map[string]any{"names": map[string]string{"en": "Mountain View"}},
+			"location":  map[string]any{"latitude": 37.386, "longitude": -122.0838, "accuracy_radius": uint16(1000), "time_zone": "America/Los_Angeles"},
+		},
+	})
+	return NewIPServiceWithProviders([]provider.Provider{geoCN, city}, DefaultMergePolicy)
+}
+
+// mergeResult 测试中比较的响应字段
+type mergeResult struct {
+	continent, country  string
+	regionCode, region  string
+	city, timeZone      string
+	latitude, longitude float64
+	isp, netType, cidr  string
+}
+
+func toMergeResult(resp *response.IPResponse) mergeResult {
+	loc := resp.Location
+	return mergeResult{
+		continent:  loc.Continent.Code,
+		country:    loc.Country.Code,
+		regionCode: loc.Region.Code,
+		region:     loc.Region.Name,
+		city:       loc.City.Name,
+		timeZone:   loc.Location.TimeZone,
+		latitude:   loc.Location.Latitude,
+		longitude:  loc.Location.Longitude,
+		isp:        resp.ISP.Name,
+		netType:    resp.Network.Type,
+		cidr:       resp.Network.CIDR,
+	}
+}
+
+// TestDefaultMergePolicy 检查默认合并策略的结果，除网段外与拆分数据源之前的查询逻辑一致
+func TestDefaultMergePolicy(t *testing.T) {
+	s := testService(t)
+
+	tests := []struct {
+		ip   string
+		want mergeResult
+	}{
+		// 地区名称包含省市，城市为空，坐标来自GeoIP2
+		{"114.114.112.1", mergeResult{
+			continent: "AS", country: "CN", regionCode: "320100", region: "江苏省南京市",
+			timeZone: "Asia/Shanghai", latitude: 32.0617, longitude: 118.7778,
+			isp: "电信", netType: "数据中心", cidr: "114.114.112.0/24",
+		}},
+		// GeoCN没有的地区和城市不从GeoIP2补充
+		{"114.114.113.1", mergeResult{
+			continent: "AS", country: "CN",
+			timeZone: "Asia/Shanghai", latitude: 32.0617, longitude: 118.7778,
+			isp: "电信", cidr: "114.114.113.0/24",
+		}},
+		// GeoCN没有记录时使用GeoIP2
+		{"114.114.114.114", mergeResult{
+			continent: "AS", country: "CN", regionCode: "JS", region: "江苏省", city: "南京",
+			timeZone: "Asia/Shanghai", latitude: 32.0617, longitude: 118.7778,
+			cidr: "114.114.0.0/16",
+		}},
+		{"8.8.8.8", mergeResult{
+			continent: "NA", country: "US", city: "Mountain View",
+			timeZone: "America/Los_Angeles", latitude: 37.386, longitude: -122.0838,
+			cidr: "8.8.8.0/24",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			resp, err := s.LookupIP(tt.ip)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := toMergeResult(resp); got != tt.want {
+				t.Errorf("LookupIP(%s) = %+v, want %+v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"ip-geo/internal/api/response"
+	"ip-geo/internal/provider"
+)
+
+// sourceHostingASN 托管服务商ASN列表的来源名称
+const sourceHostingASN = "hosting_asn"
+
+// DefaultSecuritySources 默认汇总安全标记的数据源
+var DefaultSecuritySources = []string{
+	provider.NameAnonymousIP,
+	provider.NameTorExitList,
+}
+
+// newASNSet 将ASN列表转换为集合
+func newASNSet(asns []int) map[uint]struct{} {
+	set := make(map[uint]struct{}, len(asns))
+	for _, number := range asns {
+		if number > 0 {
+			set[uint(number)] = struct{}{}
+		}
+	}
+	return set
+}
+
+// applySecurity 汇总各数据源的安全标记，并根据ASN识别托管服务商
+func (s *IPService) applySecurity(session *lookupSession, resp *response.IPResponse) {
+	for _, name := range s.securitySources {
+		record := session.record(name)
+		if record == nil {
+			continue
+		}
+		for _, flag := range record.Flags {
+			setSecurityFlag(&resp.Security, flag, name)
+		}
+	}
+
+	if resp.ASN.Number != 0 {
+		if _, ok := s.hostingASNs[resp.ASN.Number]; ok {
+			setSecurityFlag(&resp.Security, provider.FlagHostingProvider, sourceHostingASN)
+		}
+	}
+}
+
+// setSecurityFlag 设置安全标记并记录来源
+func setSecurityFlag(security *response.Security, flag, source string) {
+	switch flag {
+	case provider.FlagAnonymous:
+		security.IsAnonymous = true
+	case provider.FlagAnonymousVPN:
+		security.IsAnonymousVPN = true
+	case provider.FlagHostingProvider:
+		security.IsHostingProvider = true
+	case provider.FlagPublicProxy:
+		security.IsPublicProxy = true
+	case provider.FlagResidentialProxy:
+		security.IsResidentialProxy = true
+	case provider.FlagTorExitNode:
+		security.IsTorExitNode = true
+	default:
+		return
+	}
+
+	if security.Sources == nil {
+		security.Sources = make(map[string][]string)
+	}
+	for _, existing := range security.Sources[flag] {
+		if existing == source {
+			return
+		}
+	}
+	security.Sources[flag] = append(security.Sources[flag], source)
+}
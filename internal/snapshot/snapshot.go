@@ -0,0 +1,404 @@
+// Package snapshot 管理数据库文件的历史版本
+//
+// 每个数据库的版本保存在同目录下的versions/<名称>/中，manifest.json记录每个版本的
+// SHA-256、构建时间和下载时间。数据库路径是指向当前版本的符号链接，切换版本时
+// 先创建新的链接再重命名覆盖，正在使用的数据库和其他进程不会看到不完整的状态。
+//
+// Windows上创建符号链接需要管理员权限或开发者模式，不能创建符号链接时数据库路径是当前版本的副本，
+// 清单记录为副本方式，加载数据库时通过CurrentPath直接打开版本目录中的当前版本。
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"ip-geo/internal/logger"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// DefaultKeep 默认保留的版本数
+const DefaultKeep = 5
+
+// manifestFile 版本清单文件名
+const manifestFile = "manifest.json"
+
+// ErrNoPrevious 表示没有可以回滚的版本
+var ErrNoPrevious = errors.New("没有更早的版本")
+
+// symlink 创建符号链接，测试中替换以模拟不支持符号链接的系统
+var symlink = os.Symlink
+
+// Version 数据库的一个版本
+type Version struct {
+	ID           string    `json:"id"`
+	File         string    `json:"file"`
+	SHA256       string    `json:"sha256"`
+	Size         int64     `json:"size"`
+	DatabaseType string    `json:"database_type"`
+	BuildEpoch   uint      `json:"build_epoch"`
+	DownloadedAt time.Time `json:"downloaded_at"`
+	SourceURL    string    `json:"source_url,omitempty"`
+}
+
+// Manifest 版本清单，Versions按下载时间从新到旧排列
+type Manifest struct {
+	Current string `json:"current"`
+	// Copied 数据库路径是当前版本的副本而不是符号链接
+	Copied   bool       `json:"copied,omitempty"`
+	Versions []*Version `json:"versions"`
+}
+
+// CurrentVersion 返回当前使用的版本
+func (m *Manifest) CurrentVersion() *Version {
+	return m.find(m.Current)
+}
+
+// find 根据ID查找版本
+func (m *Manifest) find(id string) *Version {
+	for _, v := range m.Versions {
+		if v.ID == id {
+			return v
+		}
+	}
+	return nil
+}
+
+// Store 单个数据库的版本存储
+type Store struct {
+	path string
+	dir  string
+	keep int
+}
+
+// New 创建数据库文件的版本存储，keep<=0时使用DefaultKeep
+func New(path string, keep int) *Store {
+	if keep <= 0 {
+		keep = DefaultKeep
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return &Store{
+		path: path,
+		dir:  filepath.Join(filepath.Dir(path), "versions", name),
+		keep: keep,
+	}
+}
+
+// Manifest 读取版本清单，没有清单时返回空清单
+func (s *Store) Manifest() (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, manifestFile))
+	if os.IsNotExist(err) {
+		return &Manifest{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("解析版本清单失败: %v", err)
+	}
+	return m, nil
+}
+
+// Add 将下载完成的文件加入版本存储并切换为当前版本
+//
+// 文件会被移动到版本目录中。内容与已有版本相同时直接切换到已有版本。
+// 超出保留数量的旧版本会被删除，当前版本始终保留。
+func (s *Store) Add(file, sourceURL string) (*Version, error) {
+	if err := s.Import(); err != nil {
+		return nil, err
+	}
+
+	m, err := s.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := describe(file)
+	if err != nil {
+		return nil, err
+	}
+	v.DownloadedAt = time.Now().UTC()
+	v.SourceURL = sourceURL
+
+	for _, existing := range m.Versions {
+		if existing.SHA256 == v.SHA256 {
+			logger.Info("%s 与已有版本 %s 相同", s.path, existing.ID)
+			os.Remove(file)
+			// 记录最近一次下载成功的地址
+			if sourceURL != "" {
+				existing.SourceURL = sourceURL
+			}
+			return existing, s.activate(m, existing)
+		}
+	}
+
+	v.ID = v.DownloadedAt.Format("20060102T150405Z") + "-" + v.SHA256[:12]
+	v.File = v.ID + filepath.Ext(s.path)
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(file, filepath.Join(s.dir, v.File)); err != nil {
+		return nil, err
+	}
+
+	m.Versions = append([]*Version{v}, m.Versions...)
+	if err := s.activate(m, v); err != nil {
+		return nil, err
+	}
+	s.prune(m)
+	return v, nil
+}
+
+// Activate 切换到指定版本
+func (s *Store) Activate(id string) (*Version, error) {
+	m, err := s.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	v := m.find(id)
+	if v == nil {
+		return nil, fmt.Errorf("版本 %s 不存在", id)
+	}
+	return v, s.activate(m, v)
+}
+
+// VersionPath 返回指定版本在版本目录中的文件路径
+func (s *Store) VersionPath(id string) (string, error) {
+	m, err := s.Manifest()
+	if err != nil {
+		return "", err
+	}
+	v := m.find(id)
+	if v == nil {
+		return "", fmt.Errorf("版本 %s 不存在", id)
+	}
+	return filepath.Join(s.dir, v.File), nil
+}
+
+// CurrentPath 返回加载数据库时打开的文件路径
+//
+// 数据库路径是当前版本的副本时返回版本目录中的当前版本，替换副本时正在使用的文件不受影响；
+// 其他情况返回数据库路径。
+func (s *Store) CurrentPath() string {
+	m, err := s.Manifest()
+	if err != nil || !m.Copied {
+		return s.path
+	}
+	v := m.CurrentVersion()
+	if v == nil {
+		return s.path
+	}
+	return filepath.Join(s.dir, v.File)
+}
+
+// Rollback 切换到当前版本之前的一个版本
+func (s *Store) Rollback() (*Version, error) {
+	m, err := s.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range m.Versions {
+		if v.ID == m.Current {
+			if i+1 >= len(m.Versions) {
+				return nil, ErrNoPrevious
+			}
+			previous := m.Versions[i+1]
+			return previous, s.activate(m, previous)
+		}
+	}
+	return nil, ErrNoPrevious
+}
+
+// Import 将不受版本管理的普通数据库文件导入为第一个版本
+func (s *Store) Import() error {
+	info, err := os.Lstat(s.path)
+	if os.IsNotExist(err) || err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	m, err := s.Manifest()
+	if err != nil {
+		return err
+	}
+
+	v, err := describe(s.path)
+	if err != nil {
+		return fmt.Errorf("导入 %s 失败: %v", s.path, err)
+	}
+	// 不能创建符号链接时数据库路径是当前版本的副本，不需要导入
+	if current := m.CurrentVersion(); current != nil && current.SHA256 == v.SHA256 {
+		return nil
+	}
+	v.DownloadedAt = info.ModTime().UTC()
+	v.ID = v.DownloadedAt.Format("20060102T150405Z") + "-" + v.SHA256[:12]
+	v.File = v.ID + filepath.Ext(s.path)
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	// 先链接到版本目录再替换为符号链接，数据库路径始终存在
+	if err := linkOrCopy(s.path, filepath.Join(s.dir, v.File)); err != nil {
+		return err
+	}
+
+	m.Versions = append([]*Version{v}, m.Versions...)
+	logger.Info("已将 %s 导入为版本 %s", s.path, v.ID)
+	return s.activate(m, v)
+}
+
+// activate 将数据库路径指向指定版本并更新清单
+//
+// Windows上或者创建符号链接失败时，将当前版本复制到数据库路径。
+func (s *Store) activate(m *Manifest, v *Version) error {
+	file := filepath.Join(s.dir, v.File)
+	target, err := filepath.Rel(filepath.Dir(s.path), file)
+	if err != nil {
+		return err
+	}
+
+	link := s.path + ".link"
+	os.Remove(link)
+	copied := runtime.GOOS == "windows"
+	if !copied {
+		if err := symlink(target, link); err != nil {
+			logger.Warn("创建符号链接失败，改为复制当前版本: %v", err)
+			copied = true
+		}
+	}
+	if copied {
+		// 不使用硬链接，替换数据库路径时不能影响版本目录中正在使用的文件
+		if err := copyFile(file, link); err != nil {
+			os.Remove(link)
+			return fmt.Errorf("复制版本文件失败: %v", err)
+		}
+	}
+	if err := os.Rename(link, s.path); err != nil {
+		os.Remove(link)
+		return fmt.Errorf("切换版本失败: %v", err)
+	}
+
+	m.Current = v.ID
+	m.Copied = copied
+	if err := s.writeManifest(m); err != nil {
+		return err
+	}
+	logger.Info("%s 已切换到版本 %s", s.path, v.ID)
+	return nil
+}
+
+// prune 删除超出保留数量的旧版本
+func (s *Store) prune(m *Manifest) {
+	if len(m.Versions) <= s.keep {
+		return
+	}
+
+	kept := make([]*Version, 0, s.keep)
+	var removed []*Version
+	for i, v := range m.Versions {
+		if i < s.keep || v.ID == m.Current {
+			kept = append(kept, v)
+		} else {
+			removed = append(removed, v)
+		}
+	}
+
+	m.Versions = kept
+	if err := s.writeManifest(m); err != nil {
+		logger.Warn("更新版本清单失败: %v", err)
+		return
+	}
+	for _, v := range removed {
+		if err := os.Remove(filepath.Join(s.dir, v.File)); err != nil {
+			logger.Warn("删除旧版本 %s 失败: %v", v.ID, err)
+		}
+	}
+}
+
+// writeManifest 写入版本清单，先写临时文件再重命名
+func (s *Store) writeManifest(m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.dir, manifestFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// describe 计算文件的SHA-256并读取数据库元数据
+func describe(file string) (*Version, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := maxminddb.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("无效的数据库文件: %v", err)
+	}
+	defer reader.Close()
+
+	return &Version{
+		SHA256:       hex.EncodeToString(h.Sum(nil)),
+		Size:         size,
+		DatabaseType: reader.Metadata.DatabaseType,
+		BuildEpoch:   reader.Metadata.BuildEpoch,
+	}, nil
+}
+
+// linkOrCopy 优先创建硬链接，不支持时复制文件
+func linkOrCopy(src, dst string) error {
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+	return copyFile(src, dst)
+}
+
+// copyFile 复制文件
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,143 @@
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"ip-geo/internal/mmdbwriter"
+)
+
+// writeTestDB 在dir中写入一个内容由city决定的数据库文件
+func writeTestDB(t *testing.T, dir, city string) string {
+	t.Helper()
+	tree := mmdbwriter.New(mmdbwriter.Metadata{DatabaseType: "Test-City", Languages: []string{"en"}})
+	_, network, _ := net.ParseCIDR("192.0.2.0/24")
+	if err := tree.Insert(network, map[string]any{"city": city}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, city+".mmdb.tmp")
+	if err := tree.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// sameContent 判断两个文件内容是否相同
+func sameContent(t *testing.T, a, b string) bool {
+	t.Helper()
+	da, err := os.ReadFile(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := os.ReadFile(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.Equal(da, db)
+}
+
+// addVersions 依次加入两个不同的版本
+func addVersions(t *testing.T, s *Store, dir string) (first, second *Version) {
+	t.Helper()
+	first, err := s.Add(writeTestDB(t, dir, "first"), "")
+	if err != nil {
+		t.Fatalf("Add first: %v", err)
+	}
+	second, err = s.Add(writeTestDB(t, dir, "second"), "")
+	if err != nil {
+		t.Fatalf("Add second: %v", err)
+	}
+	if first.ID == second.ID {
+		t.Fatalf("versions have the same ID %s", first.ID)
+	}
+	return first, second
+}
+
+func TestActivateSymlink(t *testing.T) {
+	dir := t.TempDir()
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows上总是复制当前版本")
+	}
+	path := filepath.Join(dir, "City.mmdb")
+	s := New(path, 0)
+
+	first, second := addVersions(t, s, dir)
+
+	for _, want := range []*Version{second, first} {
+		if want == first {
+			if _, err := s.Rollback(); err != nil {
+				t.Fatalf("Rollback: %v", err)
+			}
+		}
+		info, err := os.Lstat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			t.Fatalf("%s is not a symlink", path)
+		}
+		if got := s.CurrentPath(); got != path {
+			t.Errorf("CurrentPath = %s, want %s", got, path)
+		}
+		if !sameContent(t, path, filepath.Join(s.dir, want.File)) {
+			t.Errorf("%s does not point to version %s", path, want.ID)
+		}
+	}
+}
+
+func TestActivateCopyWithoutSymlink(t *testing.T) {
+	symlink = func(oldname, newname string) error {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: errors.New("not permitted")}
+	}
+	t.Cleanup(func() { symlink = os.Symlink })
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "City.mmdb")
+	s := New(path, 0)
+
+	first, second := addVersions(t, s, dir)
+
+	for _, want := range []*Version{second, first} {
+		if want == first {
+			if _, err := s.Rollback(); err != nil {
+				t.Fatalf("Rollback: %v", err)
+			}
+		}
+		info, err := os.Lstat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.Mode().IsRegular() {
+			t.Fatalf("%s is not a regular file", path)
+		}
+		versionFile := filepath.Join(s.dir, want.File)
+		if !sameContent(t, path, versionFile) {
+			t.Errorf("%s is not a copy of version %s", path, want.ID)
+		}
+		// 加载时直接打开版本目录中的当前版本
+		if got := s.CurrentPath(); got != versionFile {
+			t.Errorf("CurrentPath = %s, want %s", got, versionFile)
+		}
+	}
+
+	m, err := s.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Copied || m.Current != first.ID {
+		t.Errorf("manifest current = %s, copied = %v", m.Current, m.Copied)
+	}
+
+	// 当前版本的副本不应当被当作普通文件再次导入
+	if err := s.Import(); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if m, err := s.Manifest(); err != nil || len(m.Versions) != 2 {
+		t.Errorf("Import added versions: %v, %v", m, err)
+	}
+}
@@ -0,0 +1,107 @@
+package whois
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ip-geo/internal/api/response"
+)
+
+// bulkHeader 批量模式摘要的表头
+const bulkHeader = "AS      | IP                                      | Network             | CC | AS Name\r\n"
+
+// writeComment 写出以%开头的注释行
+func writeComment(w *bufio.Writer, text string) {
+	fmt.Fprintf(w, "%% %s\r\n", text)
+}
+
+// writeSummary 写出一行摘要，格式与Team Cymru的批量查询相同
+func writeSummary(w *bufio.Writer, resp *response.IPResponse) {
+	asn := "NA"
+	if resp.ASN.Number != 0 {
+		asn = strconv.FormatUint(uint64(resp.ASN.Number), 10)
+	}
+	fmt.Fprintf(w, "%-7s | %-39s | %-19s | %-2s | %s\r\n",
+		asn, resp.IP, orNA(resp.Network.CIDR), orNA(resp.Location.Country.Code), orNA(resp.ASN.Name))
+}
+
+// writeBlock 写出IPResponse的所有非空字段，每行一个字段
+func writeBlock(w *bufio.Writer, resp *response.IPResponse) {
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(w, "%-20s%s\r\n", name+":", value)
+		}
+	}
+	loc := &resp.Location
+
+	field("ip", resp.IP)
+	field("version", resp.Version)
+	if resp.ASN.Number != 0 {
+		field("origin", "AS"+strconv.FormatUint(uint64(resp.ASN.Number), 10))
+	}
+	field("as-name", resp.ASN.Name)
+	field("as-info", resp.ASN.Info)
+	field("network", resp.Network.CIDR)
+	if resp.Network.StartIP != "" {
+		field("range", resp.Network.StartIP+" - "+resp.Network.EndIP)
+	}
+	if resp.Network.TotalIPs != 0 {
+		field("total-ips", strconv.FormatUint(resp.Network.TotalIPs, 10))
+	}
+	field("network-type", resp.Network.Type)
+	field("continent", codeName(loc.Continent.Code, loc.Continent.Name))
+	field("country", codeName(loc.Country.Code, loc.Country.Name))
+	field("region", codeName(loc.Region.Code, loc.Region.Name))
+	field("city", loc.City.Name)
+	if loc.Location.Latitude != 0 || loc.Location.Longitude != 0 {
+		field("coordinates", fmt.Sprintf("%g, %g", loc.Location.Latitude, loc.Location.Longitude))
+	}
+	if loc.Location.AccuracyRadius != 0 {
+		field("accuracy-radius", fmt.Sprintf("%d km", loc.Location.AccuracyRadius))
+	}
+	field("timezone", loc.Location.TimeZone)
+	field("isp", resp.ISP.Name)
+	field("isp-type", resp.ISP.Type)
+	field("security", strings.Join(securityFlags(&resp.Security), ", "))
+	field("tags", strings.Join(resp.Tags, ", "))
+	field("overrides", strings.Join(resp.Overrides, ", "))
+	field("fallback", strings.Join(resp.Fallback, ", "))
+	field("unavailable", strings.Join(resp.SourcesUnavailable, ", "))
+	w.WriteString("\r\n")
+}
+
+// securityFlags 返回为true的匿名网络标记
+func securityFlags(s *response.Security) []string {
+	var flags []string
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"anonymous", s.IsAnonymous},
+		{"anonymous-vpn", s.IsAnonymousVPN},
+		{"hosting-provider", s.IsHostingProvider},
+		{"public-proxy", s.IsPublicProxy},
+		{"residential-proxy", s.IsResidentialProxy},
+		{"tor-exit-node", s.IsTorExitNode},
+	} {
+		if f.set {
+			flags = append(flags, f.name)
+		}
+	}
+	return flags
+}
+
+// codeName 合并代码和名称，如"US 美国"
+func codeName(code, name string) string {
+	return strings.TrimSpace(code + " " + name)
+}
+
+// orNA 空字符串返回NA
+func orNA(s string) string {
+	if s == "" {
+		return "NA"
+	}
+	return s
+}
@@ -0,0 +1,219 @@
+// Package whois 提供RFC 3912 whois协议的查询服务
+//
+// 客户端连接后发送一行查询，服务端返回结果后关闭连接。第一行为begin时进入批量模式，
+// 之后每行一个IP，直到end或连接关闭，与Team Cymru的whois服务相同。批量模式中单独一行verbose
+// 表示之后的结果使用与单个查询相同的详细格式。
+package whois
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"ip-geo/internal/config"
+	"ip-geo/internal/logger"
+	"ip-geo/internal/ratelimit"
+	"ip-geo/internal/service"
+)
+
+// 默认限制
+const (
+	defaultMaxConnections = 100
+	defaultMaxBulk        = 10000
+	defaultIdleTimeout    = 30 * time.Second
+	// maxLine 单行查询的最大长度
+	maxLine = 1024
+)
+
+// 批量模式的控制命令
+const (
+	cmdBegin   = "begin"
+	cmdEnd     = "end"
+	cmdVerbose = "verbose"
+)
+
+// Server whois服务，与HTTP接口共享IPService和速率限制
+type Server struct {
+	ipService   *service.IPService
+	limiter     *ratelimit.Limiter
+	maxBulk     int
+	idleTimeout time.Duration
+	// conns 当前连接数的信号量
+	conns chan struct{}
+}
+
+// NewServer 根据配置创建whois服务，未设置的限制使用默认值
+func NewServer(cfg *config.WhoisConfig, ipService *service.IPService, limiter *ratelimit.Limiter) *Server {
+	s := &Server{
+		ipService:   ipService,
+		limiter:     limiter,
+		maxBulk:     cfg.MaxBulk,
+		idleTimeout: time.Duration(cfg.IdleTimeout) * time.Second,
+	}
+	maxConns := cfg.MaxConnections
+	if maxConns <= 0 {
+		maxConns = defaultMaxConnections
+	}
+	s.conns = make(chan struct{}, maxConns)
+	if s.maxBulk <= 0 {
+		s.maxBulk = defaultMaxBulk
+	}
+	if s.idleTimeout <= 0 {
+		s.idleTimeout = defaultIdleTimeout
+	}
+	return s
+}
+
+// ListenAndServe 按配置启动whois服务
+func ListenAndServe(cfg *config.WhoisConfig) error {
+	ln, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return err
+	}
+	logger.Info("whois服务启动在 %s", ln.Addr())
+	s := NewServer(cfg, service.GetInstance(), ratelimit.GetInstance())
+	return fmt.Errorf("whois服务停止: %v", s.Serve(ln))
+}
+
+// Serve 接受连接并在单独的goroutine中处理，连接数达到上限时返回错误信息后关闭连接
+func (s *Server) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+
+		select {
+		case s.conns <- struct{}{}:
+		default:
+			conn.SetWriteDeadline(time.Now().Add(time.Second))
+			io.WriteString(conn, "% 连接数已达上限，请稍后再试\r\n")
+			conn.Close()
+			continue
+		}
+		go func() {
+			defer func() { <-s.conns }()
+			s.handle(conn)
+		}()
+	}
+}
+
+// handle 处理单个连接
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	key := ratelimit.ClientKey(conn.RemoteAddr().String())
+	r := bufio.NewReaderSize(conn, maxLine)
+	w := bufio.NewWriter(conn)
+	defer w.Flush()
+
+	conn.SetDeadline(time.Now().Add(s.idleTimeout))
+	query, err := readLine(r)
+	if err != nil && (err != io.EOF || query == "") {
+		logger.Debug("读取whois查询失败: %s: %v", conn.RemoteAddr(), err)
+		if errors.Is(err, bufio.ErrBufferFull) {
+			writeComment(w, "查询过长")
+		}
+		return
+	}
+	logger.Debug("whois查询: %s 来自 %s", query, conn.RemoteAddr())
+
+	if !strings.EqualFold(query, cmdBegin) {
+		if !s.limiter.Allow(key) {
+			writeComment(w, "查询过于频繁，请稍后再试")
+			return
+		}
+		writeComment(w, "IP-Geo whois")
+		w.WriteString("\r\n")
+		s.lookup(w, query, true)
+		return
+	}
+	s.bulk(conn, r, w, key)
+}
+
+// bulk 批量模式，每个IP消耗一次限额，超过速率限制时等待
+func (s *Server) bulk(conn net.Conn, r *bufio.Reader, w *bufio.Writer, key string) {
+	verbose := false
+	headerWritten := false
+	count := 0
+	for {
+		conn.SetDeadline(time.Now().Add(s.idleTimeout))
+		line, err := readLine(r)
+		if errors.Is(err, bufio.ErrBufferFull) {
+			writeComment(w, "查询过长")
+			return
+		}
+		if line != "" {
+			switch {
+			case strings.EqualFold(line, cmdEnd):
+				return
+			case strings.EqualFold(line, cmdVerbose):
+				verbose = true
+			default:
+				if count >= s.maxBulk {
+					writeComment(w, fmt.Sprintf("批量查询最多 %d 个IP", s.maxBulk))
+					return
+				}
+				if err := s.wait(key); err != nil {
+					writeComment(w, "查询过于频繁，请稍后再试")
+					return
+				}
+				count++
+				if !verbose && !headerWritten {
+					w.WriteString(bulkHeader)
+					headerWritten = true
+				}
+				s.lookup(w, line, verbose)
+			}
+		}
+		if err != nil {
+			return
+		}
+		// 没有待处理的输入时写出结果，便于交互使用
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// wait 等待速率限制的令牌，最多等待空闲超时时间
+func (s *Server) wait(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.idleTimeout)
+	defer cancel()
+	return s.limiter.Wait(ctx, key)
+}
+
+// lookup 查询IP并写出结果，verbose为false时写出一行摘要
+func (s *Server) lookup(w *bufio.Writer, ip string, verbose bool) {
+	resp, err := s.ipService.LookupIP(ip)
+	if err != nil {
+		if !errors.Is(err, service.ErrInvalidIP) {
+			logger.Error("whois查询 %s 失败: %v", ip, err)
+		}
+		writeComment(w, fmt.Sprintf("%s: %v", ip, err))
+		return
+	}
+	if verbose {
+		writeBlock(w, resp)
+		return
+	}
+	writeSummary(w, resp)
+}
+
+// readLine 读取一行并去掉首尾空白，超过maxLine时返回bufio.ErrBufferFull
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadSlice('\n')
+	return string(bytes.TrimSpace(line)), err
+}
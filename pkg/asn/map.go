@@ -1,5 +1,7 @@
 package asn
 
+import "slices"
+
 // Map 包含ASN号码到运营商名称的映射
 var Map = map[int]string{
 	9812:  "东方有线",
@@ -125,7 +127,16 @@ var Map = map[int]string{
 	135061: "中国联通",
 	139007: "中国联通",
 
-	// 云服务提供商
+	// 其他地区运营商
+	4609:   "澳門電訊",
+	134773: "珠江宽频",
+	1659:   "台湾教育网",
+	17421:  "中华电信",
+	3462:   "HiNet",
+}
+
+// cloudProviders 云服务提供商的ASN，同时加入Map并作为HostingASNs
+var cloudProviders = map[int]string{
 	59019:  "金山云",
 	135377: "优刻云",
 	45062:  "网易云",
@@ -139,12 +150,7 @@ var Map = map[int]string{
 	58519:  "华为云",
 	55990:  "华为云",
 	136907: "华为云",
-	4609:   "澳門電訊",
-	134773: "珠江宽频",
-	1659:   "台湾教育网",
 	8075:   "微软云",
-	17421:  "中华电信",
-	3462:   "HiNet",
 	13335:  "Cloudflare",
 	55960:  "亚马逊云",
 	14618:  "亚马逊云",
@@ -154,6 +160,25 @@ var Map = map[int]string{
 	36492:  "谷歌云",
 }
 
+// HostingASNs 云服务提供商的ASN，用于识别托管/数据中心流量，由cloudProviders生成
+var HostingASNs = sortedKeys(cloudProviders)
+
+func init() {
+	for number, name := range cloudProviders {
+		Map[number] = name
+	}
+}
+
+// sortedKeys 返回按升序排列的ASN
+func sortedKeys(m map[int]string) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
 // 中国特别行政区
 var SpecialRegions = []string{"香港", "澳门", "台湾"}
 